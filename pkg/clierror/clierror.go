@@ -0,0 +1,229 @@
+// Package clierror classifies command failures into categories with stable
+// process exit codes so scripts can tell auth failures from SQL errors.
+//
+// Exit codes:
+//
+//	0    success
+//	1    internal or unclassified error
+//	2    usage error (bad flag, argument, or unknown command)
+//	3    configuration error (missing/invalid config, no active connection)
+//	4    authentication error
+//	5    network error (DNS, TLS, proxy, timeouts talking to Snowflake)
+//	6    SQL error reported by Snowflake
+//	7    object or connection not found
+//	8    statement refused by connection policy (readOnly)
+//	130  cancelled (Ctrl-C or Snowflake query cancellation)
+package clierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/snowflakedb/gosnowflake"
+)
+
+// Category groups failures by what the user needs to fix.
+type Category string
+
+const (
+	CategoryInternal  Category = "internal"
+	CategoryUsage     Category = "usage"
+	CategoryConfig    Category = "config"
+	CategoryAuth      Category = "auth"
+	CategoryNetwork   Category = "network"
+	CategorySQL       Category = "sql"
+	CategoryNotFound  Category = "not_found"
+	CategoryPolicy    Category = "policy"
+	CategoryCancelled Category = "cancelled"
+)
+
+var exitCodes = map[Category]int{
+	CategoryInternal:  1,
+	CategoryUsage:     2,
+	CategoryConfig:    3,
+	CategoryAuth:      4,
+	CategoryNetwork:   5,
+	CategorySQL:       6,
+	CategoryNotFound:  7,
+	CategoryPolicy:    8,
+	CategoryCancelled: 130,
+}
+
+// ExitCode returns the process exit code for the category.
+func (c Category) ExitCode() int {
+	if code, ok := exitCodes[c]; ok {
+		return code
+	}
+	return 1
+}
+
+// Error is a categorized failure carrying Snowflake diagnostics when available.
+type Error struct {
+	Category Category
+	// Number is the Snowflake or driver error number, e.g. 390100.
+	Number   int
+	SQLState string
+	QueryID  string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return string(e.Category)
+	}
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// ExitCode returns the process exit code for the error's category.
+func (e *Error) ExitCode() int { return e.Category.ExitCode() }
+
+// New wraps err with the given category.
+func New(category Category, err error) *Error {
+	return &Error{Category: category, Err: err}
+}
+
+// Newf formats a message and wraps it with the given category.
+func Newf(category Category, format string, args ...any) *Error {
+	return &Error{Category: category, Err: fmt.Errorf(format, args...)}
+}
+
+// Usage marks err as a usage error.
+func Usage(err error) error {
+	if err == nil {
+		return nil
+	}
+	return New(CategoryUsage, err)
+}
+
+// NotFoundf reports a missing connection or object.
+func NotFoundf(format string, args ...any) error {
+	return Newf(CategoryNotFound, format, args...)
+}
+
+// Configf reports a configuration problem.
+func Configf(format string, args ...any) error {
+	return Newf(CategoryConfig, format, args...)
+}
+
+// Classify returns err as an *Error, inferring the category from wrapped
+// Snowflake, network, and context errors when none was assigned explicitly.
+func Classify(err error) *Error {
+	if err == nil {
+		return nil
+	}
+	res := &Error{Category: CategoryInternal, Err: err}
+
+	var sfErr *gosnowflake.SnowflakeError
+	hasSnowflake := errors.As(err, &sfErr)
+	if hasSnowflake {
+		res.Number = sfErr.Number
+		res.SQLState = sfErr.SQLState
+		res.QueryID = sfErr.QueryID
+	}
+
+	var categorized *Error
+	switch {
+	case errors.As(err, &categorized):
+		res.Category = categorized.Category
+		if res.Number == 0 {
+			res.Number = categorized.Number
+			res.SQLState = categorized.SQLState
+			res.QueryID = categorized.QueryID
+		}
+	case errors.Is(err, context.Canceled):
+		res.Category = CategoryCancelled
+	case hasSnowflake:
+		res.Category = categoryForNumber(sfErr.Number, sfErr.SQLState)
+	case errors.Is(err, context.DeadlineExceeded):
+		res.Category = CategoryNetwork
+	case isNetworkError(err):
+		res.Category = CategoryNetwork
+	case isCobraUsageError(err.Error()):
+		res.Category = CategoryUsage
+	}
+	return res
+}
+
+// categoryForNumber maps Snowflake server and gosnowflake driver error numbers to categories.
+func categoryForNumber(number int, sqlState string) Category {
+	switch {
+	case number == 604:
+		return CategoryCancelled
+	case number == 2003:
+		return CategoryNotFound
+	case number >= 390000 && number < 391000:
+		return CategoryAuth
+	case number == gosnowflake.ErrCodeEmptyPasswordCode,
+		number == gosnowflake.ErrCodeEmptyPasswordAndToken,
+		number >= gosnowflake.ErrFailedToAuth && number <= gosnowflake.ErrFailedToGetExternalBrowserResponse:
+		return CategoryAuth
+	case number == gosnowflake.ErrCodeServiceUnavailable,
+		number == gosnowflake.ErrCodeFailedToConnect,
+		number >= gosnowflake.ErrFailedToPostQuery && number <= gosnowflake.ErrFailedToCloseSession,
+		number == gosnowflake.ErrFailedToHeartbeat,
+		number == gosnowflake.ErrFailedToGetChunk:
+		return CategoryNetwork
+	case number >= 260000 && number < 261000:
+		return CategoryConfig
+	case sqlState != "" || number > 0:
+		return CategorySQL
+	default:
+		return CategoryInternal
+	}
+}
+
+func isNetworkError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
+
+// isCobraUsageError recognises the plain errors cobra returns for bad input
+// before any command code runs.
+func isCobraUsageError(msg string) bool {
+	for _, prefix := range []string{"unknown command ", "unknown flag: ", "unknown shorthand flag: ", "flag needs an argument: ", "invalid argument ", "required flag(s) "} {
+		if strings.HasPrefix(msg, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Envelope is the structured error document printed on failure.
+type Envelope struct {
+	Error    string `json:"error" yaml:"error"`
+	Category string `json:"category" yaml:"category"`
+	ExitCode int    `json:"exitCode" yaml:"exitCode"`
+	Code     string `json:"code,omitempty" yaml:"code,omitempty"`
+	SQLState string `json:"sqlState,omitempty" yaml:"sqlState,omitempty"`
+	QueryID  string `json:"queryId,omitempty" yaml:"queryId,omitempty"`
+	Hint     string `json:"hint,omitempty" yaml:"hint,omitempty"`
+}
+
+// NewEnvelope builds the printable envelope for e.
+func NewEnvelope(e *Error, hint string) Envelope {
+	env := Envelope{
+		Error:    e.Error(),
+		Category: string(e.Category),
+		ExitCode: e.ExitCode(),
+		SQLState: e.SQLState,
+		QueryID:  e.QueryID,
+		Hint:     hint,
+	}
+	if e.Number != 0 {
+		env.Code = fmt.Sprintf("%06d", e.Number)
+	}
+	return env
+}
@@ -0,0 +1,60 @@
+package clierror
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/snowflakedb/gosnowflake"
+)
+
+func TestClassifySnowflakeErrors(t *testing.T) {
+	cases := []struct {
+		number   int
+		sqlState string
+		want     Category
+	}{
+		{390100, "08004", CategoryAuth},
+		{2003, "02000", CategoryNotFound},
+		{1003, "42000", CategorySQL},
+		{604, "57014", CategoryCancelled},
+		{gosnowflake.ErrFailedToPostQuery, "", CategoryNetwork},
+		{gosnowflake.ErrCodeFailedToParseHost, "", CategoryConfig},
+	}
+	for _, tc := range cases {
+		sfErr := &gosnowflake.SnowflakeError{Number: tc.number, SQLState: tc.sqlState, QueryID: "qid", Message: "boom"}
+		got := Classify(fmt.Errorf("query failed: %w", sfErr))
+		if got.Category != tc.want {
+			t.Fatalf("number %d: expected %s, got %s", tc.number, tc.want, got.Category)
+		}
+		if got.Number != tc.number || got.SQLState != tc.sqlState || got.QueryID != "qid" {
+			t.Fatalf("number %d: diagnostics not unwrapped: %+v", tc.number, got)
+		}
+	}
+}
+
+func TestClassifyExplicitAndGenericErrors(t *testing.T) {
+	if got := Classify(NotFoundf("connection %q not found", "x")); got.Category != CategoryNotFound || got.ExitCode() != 7 {
+		t.Fatalf("expected not found with exit 7, got %+v", got)
+	}
+	if got := Classify(fmt.Errorf("wrap: %w", New(CategoryPolicy, errors.New("read-only")))); got.Category != CategoryPolicy || got.ExitCode() != 8 {
+		t.Fatalf("expected policy with exit 8, got %+v", got)
+	}
+	if got := Classify(fmt.Errorf("wrap: %w", context.Canceled)); got.Category != CategoryCancelled || got.ExitCode() != 130 {
+		t.Fatalf("expected cancelled, got %+v", got)
+	}
+	if got := Classify(errors.New(`unknown command "foo" for "snowctl"`)); got.Category != CategoryUsage {
+		t.Fatalf("expected usage, got %+v", got)
+	}
+	if got := Classify(errors.New("boom")); got.Category != CategoryInternal || got.ExitCode() != 1 {
+		t.Fatalf("expected internal, got %+v", got)
+	}
+}
+
+func TestNewEnvelopeFormatsCode(t *testing.T) {
+	env := NewEnvelope(&Error{Category: CategoryNotFound, Number: 2003, Err: errors.New("missing")}, "hint")
+	if env.Code != "002003" || env.ExitCode != 7 || env.Category != "not_found" || env.Hint != "hint" {
+		t.Fatalf("unexpected envelope: %+v", env)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 )
 
 func newCompletionCmd(root *cobra.Command) *cobra.Command {
@@ -56,3 +58,13 @@ PowerShell:
 
 	return completionCmd
 }
+
+// completeOutputFormats offers the registered output formats for -o/--output.
+func completeOutputFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	formatters := format.All()
+	res := make([]string, 0, len(formatters))
+	for _, f := range formatters {
+		res = append(res, fmt.Sprintf("%s\t%s", f.Name(), f.Description()))
+	}
+	return res, cobra.ShellCompDirectiveNoFileComp
+}
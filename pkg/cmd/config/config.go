@@ -0,0 +1,53 @@
+package configcmd
+
+import (
+	"errors"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+// NewConfigCmd inspects and edits the snowctl configuration by dotted key.
+func NewConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "View and edit snowctl configuration",
+		Long: `Read and change individual configuration keys without re-running the connection prompts.
+Keys are dotted paths: currentContext, defaultContext, contexts.NAME, contexts.NAME.SETTING, or
+contexts.NAME.params.KEY. Values are the stored ones; settings inherited through extends are not resolved.`,
+	}
+
+	cmd.AddCommand(
+		newViewCmd(),
+		newGetCmd(),
+		newSetCmd(),
+		newUnsetCmd(),
+	)
+	return cmd
+}
+
+// classify maps config path errors onto CLI error categories.
+func classify(err error) error {
+	if errors.Is(err, config.ErrNotFound) || errors.Is(err, config.ErrNotSet) {
+		return clierror.New(clierror.CategoryNotFound, err)
+	}
+	return clierror.Usage(err)
+}
+
+// completeKeys suggests top-level keys and per-connection settings.
+func completeKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	keys := []string{"currentContext", "defaultContext"}
+	if cfg, err := config.Load(); err == nil {
+		for _, name := range cfg.ContextNames() {
+			for _, key := range config.ContextKeys() {
+				keys = append(keys, "contexts."+name+"."+key)
+			}
+		}
+	}
+	return keys, cobra.ShellCompDirectiveNoFileComp
+}
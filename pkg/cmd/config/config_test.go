@@ -0,0 +1,100 @@
+package configcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func prepareRuntime(t *testing.T, configure func(*config.Config)) *runtime.Runtime {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	if configure != nil {
+		configure(cfg)
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	rt, err := runtime.NewRuntime("", "json")
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	return rt
+}
+
+func newCmdWithRuntime(rt *runtime.Runtime) (*cobra.Command, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	buf := &bytes.Buffer{}
+	cmd.SetOut(buf)
+	cmd.SetContext(runtime.WithRuntime(context.Background(), rt))
+	return cmd, buf
+}
+
+func TestViewRedactsSecrets(t *testing.T) {
+	rt := prepareRuntime(t, func(cfg *config.Config) {
+		cfg.SetContext("prod", &config.Context{Account: "acct", Secret: "s3cret"})
+	})
+	cmd, buf := newCmdWithRuntime(rt)
+
+	if err := runView(cmd); err != nil {
+		t.Fatalf("runView: %v", err)
+	}
+	if strings.Contains(buf.String(), "s3cret") || !strings.Contains(buf.String(), config.RedactedValue) {
+		t.Fatalf("expected redacted secret, got %s", buf.String())
+	}
+}
+
+func TestSetGetUnsetPersist(t *testing.T) {
+	rt := prepareRuntime(t, func(cfg *config.Config) {
+		cfg.SetContext("prod", &config.Context{Account: "acct", Secret: "s3cret"})
+	})
+
+	cmd, _ := newCmdWithRuntime(rt)
+	if err := runSet(cmd, "contexts.prod.warehouse", "REPORTING_WH"); err != nil {
+		t.Fatalf("runSet: %v", err)
+	}
+	loaded, _ := config.Load()
+	if loaded.Contexts["prod"].Warehouse != "REPORTING_WH" {
+		t.Fatalf("expected warehouse persisted, got %+v", loaded.Contexts["prod"])
+	}
+
+	cmd, buf := newCmdWithRuntime(rt)
+	if err := runGet(cmd, "contexts.prod.warehouse", false); err != nil {
+		t.Fatalf("runGet: %v", err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &payload); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if payload["value"] != "REPORTING_WH" {
+		t.Fatalf("unexpected get payload: %v", payload)
+	}
+
+	cmd, _ = newCmdWithRuntime(rt)
+	if err := runUnset(cmd, "contexts.prod.warehouse"); err != nil {
+		t.Fatalf("runUnset: %v", err)
+	}
+	cmd, _ = newCmdWithRuntime(rt)
+	err := runGet(cmd, "contexts.prod.warehouse", false)
+	var cliErr *clierror.Error
+	if !errors.As(err, &cliErr) || cliErr.Category != clierror.CategoryNotFound {
+		t.Fatalf("expected not_found after unset, got %v", err)
+	}
+
+	cmd, _ = newCmdWithRuntime(rt)
+	err = runSet(cmd, "contexts.prod.port", "http")
+	if !errors.As(err, &cliErr) || cliErr.Category != clierror.CategoryUsage {
+		t.Fatalf("expected usage error for invalid value, got %v", err)
+	}
+}
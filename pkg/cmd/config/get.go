@@ -0,0 +1,40 @@
+package configcmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newGetCmd() *cobra.Command {
+	var raw bool
+	cmd := &cobra.Command{
+		Use:   "get KEY",
+		Short: "Print one configuration value",
+		Example: `snowctl config get currentContext
+snowctl config get contexts.prod.role`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGet(cmd, args[0], raw)
+		},
+	}
+	cmd.Flags().BoolVar(&raw, "raw", false, "Show secrets and proxy passwords instead of redacting them")
+	return cmd
+}
+
+func runGet(cmd *cobra.Command, key string, raw bool) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	value, err := rt.Config.Get(key, raw)
+	if err != nil {
+		return classify(err)
+	}
+	return output.Print(cmd, map[string]any{
+		"key":   key,
+		"value": value,
+	})
+}
@@ -0,0 +1,45 @@
+package configcmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set one configuration value without re-testing the connection",
+		Example: `snowctl config set contexts.prod.warehouse REPORTING_WH
+snowctl config set contexts.prod.params.QUERY_TAG nightly
+snowctl config set currentContext prod`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSet(cmd, args[0], args[1])
+		},
+	}
+	return cmd
+}
+
+func runSet(cmd *cobra.Command, key, value string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := rt.Config.Set(key, value); err != nil {
+		return classify(err)
+	}
+	if err := config.Save(rt.Config); err != nil {
+		return err
+	}
+	display, _ := rt.Config.Get(key, false)
+	path, _ := config.Path()
+	return output.Print(cmd, map[string]any{
+		"key":     key,
+		"value":   display,
+		"savedAt": path,
+	})
+}
@@ -0,0 +1,41 @@
+package configcmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newUnsetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset KEY",
+		Short: "Remove one configuration value",
+		Example: `snowctl config unset contexts.prod.warehouse
+snowctl config unset contexts.prod.params.QUERY_TAG`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeKeys,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runUnset(cmd, args[0])
+		},
+	}
+	return cmd
+}
+
+func runUnset(cmd *cobra.Command, key string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if err := rt.Config.Unset(key); err != nil {
+		return classify(err)
+	}
+	if err := config.Save(rt.Config); err != nil {
+		return err
+	}
+	return output.Print(cmd, map[string]string{
+		"key":    key,
+		"status": "unset",
+	})
+}
@@ -0,0 +1,35 @@
+package configcmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newViewCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective configuration with secrets redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runView(cmd)
+		},
+	}
+	return cmd
+}
+
+func runView(cmd *cobra.Command) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	tree, err := rt.Config.Redacted()
+	if err != nil {
+		return err
+	}
+	if _, ok := tree["contexts"]; !ok {
+		tree["contexts"] = map[string]any{}
+	}
+	return output.Print(cmd, tree)
+}
@@ -12,11 +12,16 @@ func NewConnectionCmd() *cobra.Command {
 
 	cmd.AddCommand(
 		newSetConnectionCmd(),
+		newEditConnectionCmd(),
 		newListConnectionsCmd(),
 		newUseConnectionCmd(),
 		newRemoveConnectionCmd(),
+		newRenameConnectionCmd(),
+		newCopyConnectionCmd(),
 		newSetDefaultConnectionCmd(),
 		newTestConnectionCmd(),
+		newImportConnectionCmd(),
+		newExportConnectionCmd(),
 	)
 
 	return cmd
@@ -0,0 +1,81 @@
+package connectioncmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newCopyConnectionCmd() *cobra.Command {
+	opts := &copyOptions{}
+
+	cmd := &cobra.Command{
+		Use:     "copy SRC DST",
+		Aliases: []string{"cp"},
+		Short:   "Copy a saved connection under a new name",
+		Long: `Copy a saved connection, including its secret and extends, under a new name.
+Flags override individual settings of the copy; the secret is never printed.`,
+		Example: `snowctl connection copy analytics analytics-etl --role LOADER --warehouse ETL_WH`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd, strings.TrimSpace(args[0]), strings.TrimSpace(args[1]))
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.role, "role", "", "Role for the copy")
+	cmd.Flags().StringVar(&opts.warehouse, "warehouse", "", "Warehouse for the copy")
+	cmd.Flags().StringVar(&opts.database, "database", "", "Database for the copy")
+	cmd.Flags().StringVar(&opts.schema, "schema", "", "Schema for the copy")
+	return cmd
+}
+
+type copyOptions struct {
+	role      string
+	warehouse string
+	database  string
+	schema    string
+}
+
+func (o *copyOptions) run(cmd *cobra.Command, src, dst string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	ctx, err := rt.Config.CopyContext(src, dst)
+	if err != nil {
+		return connectionNameError(err)
+	}
+	overrides := []struct {
+		flag  string
+		value string
+		field *string
+	}{
+		{"role", o.role, &ctx.Role},
+		{"warehouse", o.warehouse, &ctx.Warehouse},
+		{"database", o.database, &ctx.Database},
+		{"schema", o.schema, &ctx.Schema},
+	}
+	for _, ov := range overrides {
+		if cmd.Flags().Changed(ov.flag) {
+			*ov.field = strings.TrimSpace(ov.value)
+		}
+	}
+	if err := config.Save(rt.Config); err != nil {
+		return err
+	}
+
+	resp := map[string]any{
+		"connection":   dst,
+		"copiedFrom":   src,
+		"secretCopied": ctx.Secret != "",
+		"savedAt":      connectionsLocation(),
+	}
+	if ctx.Extends != "" {
+		resp["extends"] = ctx.Extends
+	}
+	return output.Print(cmd, resp)
+}
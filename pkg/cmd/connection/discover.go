@@ -0,0 +1,311 @@
+package connectioncmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
+)
+
+// pickerLimit is the number of choices listed before the picker asks the
+// user to type part of a name instead.
+const pickerLimit = 20
+
+var (
+	plainIdentifier  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+	quotedIdentifier = regexp.MustCompile(`^"([^"]|"")+"$`)
+)
+
+// sessionObject is a role, warehouse, database, or schema setting of
+// connection set.
+type sessionObject struct {
+	kind      string
+	label     string
+	target    *string
+	flagValue string
+}
+
+// resolveObjects fills the role, warehouse, database, and schema of ctx. It
+// logs in with the credentials collected so far to list what the user can
+// access: interactive runs pick from those lists, and values passed by flag
+// are checked against them with a warning when they are not accessible.
+// Listing failures other than a failed login only downgrade to a plain
+// prompt, since the final connection test still validates the result. All
+// listings share one login.
+func (o *setConnectionOptions) resolveObjects(cmd *cobra.Command, reader *bufio.Reader, ctx *config.Context, envDefaults map[string]string, interactive bool) error {
+	objects := []sessionObject{
+		{kind: "role", label: "Default role", target: &ctx.Role, flagValue: o.role},
+		{kind: "warehouse", label: "Default warehouse", target: &ctx.Warehouse, flagValue: o.warehouse},
+		{kind: "database", label: "Default database", target: &ctx.Database, flagValue: o.database},
+		{kind: "schema", label: "Default schema", target: &ctx.Schema, flagValue: o.schema},
+	}
+	errOut := cmd.ErrOrStderr()
+	styler := output.Styler(cmd, errOut)
+	lister := &discovery{info: ctx}
+	defer lister.close()
+
+	for _, obj := range objects {
+		value := strings.TrimSpace(*obj.target)
+		flagged := cmd.Flags().Changed(obj.kind)
+		if flagged {
+			value = strings.TrimSpace(obj.flagValue)
+		}
+		if value == "" {
+			value = strings.TrimSpace(envDefaults[obj.kind])
+		}
+
+		var choices []string
+		var listErr error
+		canList := obj.kind != "schema" || ctx.Database != ""
+		if canList && (interactive || (flagged && value != "")) {
+			choices, listErr = lister.list(cmd.Context(), obj.kind)
+			if listErr != nil && clierror.Classify(listErr).Category == clierror.CategoryAuth {
+				return fmt.Errorf("connection validation failed: %w", listErr)
+			}
+		}
+		if flagged && value != "" {
+			switch {
+			case listErr != nil:
+				fmt.Fprintf(errOut, "%s could not verify %s %s: %v\n", styler.Warning("warning:"), obj.kind, value, listErr)
+			case !containsIdentifier(choices, value):
+				fmt.Fprintf(errOut, "%s %s %s is not accessible to user %s (it does not exist or is not granted)\n", styler.Warning("warning:"), obj.kind, value, ctx.User)
+			}
+		}
+
+		var err error
+		switch {
+		case interactive && listErr == nil && len(choices) > 0:
+			value, err = pickObject(cmd, reader, obj.label, choices, value)
+		case interactive:
+			value, err = promptString(cmd, reader, obj.label, value, true)
+		case value == "":
+			err = fmt.Errorf("%s is required; pass --%s or run interactively", obj.label, obj.kind)
+		}
+		if err != nil {
+			return err
+		}
+		*obj.target = value
+	}
+	return nil
+}
+
+// querySession is the part of snowflake.Session that discovery uses.
+type querySession interface {
+	RunQuery(ctx context.Context, stmt string) ([]map[string]any, error)
+	Close() error
+}
+
+func openSession(ctx context.Context, info *config.Context) (querySession, error) {
+	session, err := snowflake.OpenSession(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// discovery lists the objects the user of info can use over a single
+// session. The session is opened on first use under the user's default role
+// and switched with USE ROLE once a role is chosen, so warehouses and
+// databases are listed under that role.
+type discovery struct {
+	info    *config.Context
+	session querySession
+	openErr error
+	role    string
+}
+
+func (d *discovery) run(ctx context.Context, stmt string) ([]map[string]any, error) {
+	if d.session == nil && d.openErr == nil {
+		probe := d.info.Clone()
+		probe.Role, probe.Warehouse, probe.Database, probe.Schema = "", "", "", ""
+		d.session, d.openErr = openSessionFn(ctx, probe)
+	}
+	if d.openErr != nil {
+		return nil, d.openErr
+	}
+	return d.session.RunQuery(ctx, stmt)
+}
+
+func (d *discovery) close() {
+	if d.session != nil {
+		d.session.Close()
+	}
+}
+
+// useRole switches the session to the role chosen so far.
+func (d *discovery) useRole(ctx context.Context) error {
+	role := strings.TrimSpace(d.info.Role)
+	if role == "" || snowflake.SameIdentifier(role, d.role) {
+		return nil
+	}
+	if _, err := d.run(ctx, "use role "+quoteIdentifier(role)); err != nil {
+		return err
+	}
+	d.role = role
+	return nil
+}
+
+// list returns the names of the objects of kind. Schemas are listed within
+// the database already chosen.
+func (d *discovery) list(ctx context.Context, kind string) ([]string, error) {
+	if kind == "role" {
+		rows, err := d.run(ctx, "show grants to user "+quoteIdentifier(d.info.User))
+		column := "role"
+		if err != nil {
+			if clierror.Classify(err).Category == clierror.CategoryAuth {
+				return nil, err
+			}
+			// Falls back for user names that cannot be addressed directly.
+			rows, err = d.run(ctx, "show roles")
+			column = "name"
+		}
+		if err != nil {
+			return nil, err
+		}
+		names := columnValues(rows, column)
+		if !containsIdentifier(names, "PUBLIC") {
+			names = append(names, "PUBLIC")
+		}
+		return names, nil
+	}
+
+	if err := d.useRole(ctx); err != nil {
+		return nil, err
+	}
+	stmt := map[string]string{
+		"warehouse": "show warehouses",
+		"database":  "show databases",
+		"schema":    "show schemas in database " + quoteIdentifier(d.info.Database),
+	}[kind]
+	rows, err := d.run(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return columnValues(rows, "name"), nil
+}
+
+// columnValues returns the distinct non-empty values of column, matched
+// case-insensitively since SHOW output uses lower-case column names.
+func columnValues(rows []map[string]any, column string) []string {
+	var values []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for key, v := range row {
+			if !strings.EqualFold(key, column) || v == nil {
+				continue
+			}
+			s := strings.TrimSpace(fmt.Sprint(v))
+			if s != "" && !seen[s] {
+				seen[s] = true
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
+
+// pickObject offers choices as a numbered list. The answer may be a number,
+// a name, or part of a name to narrow the list; names that are not listed are
+// only accepted after confirmation.
+func pickObject(cmd *cobra.Command, reader *bufio.Reader, label string, choices []string, defaultValue string) (string, error) {
+	out := cmd.OutOrStdout()
+	shown := choices
+	for {
+		fmt.Fprintf(out, "%s (%d available):\n", label, len(shown))
+		for i, c := range shown[:min(len(shown), pickerLimit)] {
+			fmt.Fprintf(out, "  %d) %s\n", i+1, c)
+		}
+		if len(shown) > pickerLimit {
+			fmt.Fprintf(out, "  ... %d more; type part of a name to filter\n", len(shown)-pickerLimit)
+		}
+		answer, err := promptString(cmd, reader, "Enter number, name, or filter", defaultValue, true)
+		if err != nil {
+			return "", err
+		}
+		if idx, err := strconv.Atoi(answer); err == nil {
+			if idx >= 1 && idx <= min(len(shown), pickerLimit) {
+				return shown[idx-1], nil
+			}
+			fmt.Fprintf(out, "Selection %d out of range.\n", idx)
+			continue
+		}
+		for _, c := range choices {
+			if snowflake.SameIdentifier(answer, c) {
+				return c, nil
+			}
+		}
+		switch matches := fuzzyFilter(choices, answer); len(matches) {
+		case 0:
+			confirm, err := promptString(cmd, reader, fmt.Sprintf("%s is not accessible to this user. Use it anyway? (y/N)", answer), "", false)
+			if err != nil {
+				return "", err
+			}
+			if strings.EqualFold(confirm, "y") || strings.EqualFold(confirm, "yes") {
+				return answer, nil
+			}
+			shown = choices
+		case 1:
+			fmt.Fprintf(out, "Using %s.\n", matches[0])
+			return matches[0], nil
+		default:
+			shown = matches
+		}
+	}
+}
+
+// fuzzyFilter returns the choices containing pattern, followed by those that
+// contain its characters in order, ignoring case.
+func fuzzyFilter(choices []string, pattern string) []string {
+	pattern = strings.ToLower(strings.Trim(pattern, `"`))
+	var substring, subsequence []string
+	for _, c := range choices {
+		lower := strings.ToLower(c)
+		switch {
+		case strings.Contains(lower, pattern):
+			substring = append(substring, c)
+		case isSubsequence(pattern, lower):
+			subsequence = append(subsequence, c)
+		}
+	}
+	return append(substring, subsequence...)
+}
+
+func isSubsequence(pattern, s string) bool {
+	for _, r := range s {
+		if pattern == "" {
+			break
+		}
+		if first := []rune(pattern)[0]; r == first {
+			pattern = pattern[len(string(first)):]
+		}
+	}
+	return pattern == ""
+}
+
+func containsIdentifier(names []string, name string) bool {
+	for _, n := range names {
+		if snowflake.SameIdentifier(name, n) {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteIdentifier returns name as a SQL identifier: plain identifiers and
+// well-formed quoted identifiers are kept, anything else is quoted with
+// embedded quotes doubled.
+func quoteIdentifier(name string) string {
+	if plainIdentifier.MatchString(name) || quotedIdentifier.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,279 @@
+package connectioncmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	goruntime "runtime"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+// editErrorMarker starts the comments that report problems in the edited
+// file. They are stripped before the file is parsed again.
+const editErrorMarker = "# ERROR: "
+
+var tableHeader = regexp.MustCompile(`^\s*\[\s*([^\]]+?)\s*\]\s*$`)
+
+func newEditConnectionCmd() *cobra.Command {
+	opts := &editOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "edit NAME",
+		Short: "Edit a saved connection in $EDITOR",
+		Long: `Open the settings stored on a connection as TOML in $VISUAL or $EDITOR (vi by default).
+The secret is shown as REDACTED; leave it as is to keep the stored secret. When the editor
+exits the settings are validated; problems are added as comments and the editor reopens until
+they are fixed. Saving the file unchanged leaves the connection as it was.`,
+		Example: `snowctl connection edit prod
+EDITOR="code --wait" snowctl connection edit prod --test`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd, strings.TrimSpace(args[0]))
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.test, "test", false, "Test the edited connection before saving it")
+	return cmd
+}
+
+type editOptions struct {
+	test bool
+}
+
+func (o *editOptions) run(cmd *cobra.Command, name string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	orig, ok := rt.Config.GetContext(name)
+	if !ok {
+		return clierror.NotFoundf("connection %q not found", name)
+	}
+	doc, err := config.EncodeContext(orig)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "snowctl-edit-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, name+".toml")
+
+	content := editHeader(rt, name) + string(doc)
+	var lastErr error
+	var edited *config.Context
+	var serverTime string
+	for {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return fmt.Errorf("write temp file: %w", err)
+		}
+		if err := editFn(cmd, path); err != nil {
+			return fmt.Errorf("run editor: %w", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("read temp file: %w", err)
+		}
+		if string(data) == content {
+			if lastErr != nil {
+				return lastErr
+			}
+			return output.Print(cmd, map[string]any{"connection": name, "changed": []string{}})
+		}
+
+		text := stripEditErrors(string(data))
+		if isBlankDocument(text) {
+			return clierror.Newf(clierror.CategoryCancelled, "edit cancelled: the file was emptied")
+		}
+		ctx, err := o.check(cmd, rt, name, orig, text, &serverTime)
+		if err == nil {
+			edited = ctx
+			break
+		}
+		var errs config.EditErrors
+		if !errors.As(err, &errs) {
+			return err
+		}
+		lastErr = clierror.New(clierror.CategoryConfig, fmt.Errorf("connection %q not saved: %w", name, errs))
+		content = annotateEditErrors(text, errs)
+		fmt.Fprintf(cmd.ErrOrStderr(), "%d problem(s) found; reopening the editor. Save without changes to give up.\n", len(errs))
+	}
+
+	changed, err := config.ChangedKeys(orig, edited)
+	if err != nil {
+		return err
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	resp := map[string]any{"connection": name, "changed": changed}
+	if serverTime != "" {
+		resp["serverTime"] = serverTime
+	}
+	if len(changed) > 0 {
+		rt.Config.SetContext(name, edited)
+		if err := config.Save(rt.Config); err != nil {
+			return err
+		}
+		resp["savedAt"] = connectionsLocation()
+	}
+	return output.Print(cmd, resp)
+}
+
+// check parses and validates an edited document and, with --test, logs in
+// with the result. Problems are returned as config.EditErrors.
+func (o *editOptions) check(cmd *cobra.Command, rt *runtime.Runtime, name string, orig *config.Context, text string, serverTime *string) (*config.Context, error) {
+	ctx, decodeErr := config.DecodeContext([]byte(text))
+	if ctx == nil {
+		return nil, decodeErr
+	}
+	config.RestoreRedacted(ctx, orig)
+	var errs config.EditErrors
+	errors.As(decodeErr, &errs)
+	var invalid config.EditErrors
+	if errors.As(rt.Config.ValidateContext(name, ctx), &invalid) {
+		errs = append(errs, invalid...)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if o.test {
+		resolved := ctx
+		if ctx.Extends != "" {
+			parent, _, err := rt.Config.ResolveContext(ctx.Extends)
+			if err != nil {
+				return nil, clierror.New(clierror.CategoryConfig, err)
+			}
+			resolved = ctx.Inherit(parent)
+		}
+		resolved.Name = name
+		ts, err := testConnectionFn(cmd.Context(), resolved)
+		if err != nil {
+			return nil, config.EditErrors{{Err: fmt.Errorf("connection test failed: %w", err)}}
+		}
+		*serverTime = ts
+	}
+	return ctx, nil
+}
+
+// editHeader explains the file and lists the settings inherited from the
+// parent, which are not part of the document.
+func editHeader(rt *runtime.Runtime, name string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Connection %q. Save and quit to apply; quit without saving to keep it as it is.\n", name)
+	fmt.Fprintf(&b, "# secret = '%s' keeps the stored secret; replace it to change the secret.\n", config.RedactedValue)
+	if _, inherited, err := rt.Config.ResolveContext(name); err == nil && len(inherited) > 0 {
+		keys := make([]string, 0, len(inherited))
+		for key, from := range inherited {
+			keys = append(keys, fmt.Sprintf("%s (from %s)", key, from))
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(&b, "# Inherited, add a line to override: %s\n", strings.Join(keys, ", "))
+	}
+	return b.String() + "\n"
+}
+
+func stripEditErrors(text string) string {
+	lines := strings.SplitAfter(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(line, editErrorMarker) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
+func isBlankDocument(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// annotateEditErrors inserts a comment for each problem above the line it
+// refers to, or at the top of the document when no line is known.
+func annotateEditErrors(text string, errs config.EditErrors) string {
+	lines := strings.SplitAfter(text, "\n")
+	above := map[int][]string{}
+	for _, e := range errs {
+		idx := e.Line - 1
+		if e.Line == 0 {
+			idx = keyLine(lines, e.Key)
+		}
+		if idx < 0 || idx >= len(lines) {
+			idx = 0
+		}
+		above[idx] = append(above[idx], editErrorMarker+strings.ReplaceAll(e.Error(), "\n", " ")+"\n")
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		for _, comment := range above[i] {
+			b.WriteString(comment)
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// keyLine returns the index of the line that assigns key, where key is a
+// top-level setting or table.entry, or -1.
+func keyLine(lines []string, key string) int {
+	if key == "" {
+		return -1
+	}
+	table, leaf := "", key
+	if t, l, ok := strings.Cut(key, "."); ok {
+		table, leaf = t, l
+	}
+	current := ""
+	for i, line := range lines {
+		if m := tableHeader.FindStringSubmatch(line); m != nil {
+			current = m[1]
+			continue
+		}
+		if current != table {
+			continue
+		}
+		assigned, _, ok := strings.Cut(line, "=")
+		if ok && strings.Trim(strings.TrimSpace(assigned), `'"`) == leaf {
+			return i
+		}
+	}
+	return -1
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, which may include arguments,
+// and waits for it to exit.
+func runEditor(cmd *cobra.Command, path string) error {
+	editor := strings.TrimSpace(os.Getenv("VISUAL"))
+	if editor == "" {
+		editor = strings.TrimSpace(os.Getenv("EDITOR"))
+	}
+	if editor == "" {
+		editor = "vi"
+		if goruntime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+	c := exec.CommandContext(cmd.Context(), args[0], append(args[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+	return c.Run()
+}
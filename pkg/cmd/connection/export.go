@@ -0,0 +1,127 @@
+package connectioncmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/interop"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newExportConnectionCmd() *cobra.Command {
+	opts := &exportOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "export (NAME...|-l SELECTOR) --format snowcli|snowsql|dbt|env",
+		Short: "Export connections to other tools' config formats",
+		Long: `Render snowctl connections as Snowflake CLI connections.toml tables, SnowSQL
+[connections.NAME] sections, dbt profiles.yml targets, or a .env file of SNOWFLAKE_* variables.
+Secrets are omitted unless --include-secrets is passed and confirmed.`,
+		Example: `snowctl connection export prod dev --format snowcli >> ~/.snowflake/connections.toml
+snowctl connection export prod --format env --include-secrets --yes --out-file .env
+snowctl connection export -l team=finance --format dbt`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.format, "format", "", "Export format: snowcli, snowsql, dbt, or env")
+	cmd.Flags().BoolVar(&opts.includeSecrets, "include-secrets", false, "Include stored passwords/PATs in plain text (asks for confirmation)")
+	cmd.Flags().BoolVar(&opts.yes, "yes", false, "Skip the --include-secrets confirmation (for scripts)")
+	cmd.Flags().StringVar(&opts.dbtProfile, "dbt-profile", "snowctl", "Profile name used for --format dbt")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "Export the connections whose labels match instead of naming them, e.g. env=prod")
+	_ = cmd.MarkFlagRequired("format")
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return interop.ExportFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+type exportOptions struct {
+	format         string
+	includeSecrets bool
+	yes            bool
+	dbtProfile     string
+	selector       string
+}
+
+func (o *exportOptions) run(cmd *cobra.Command, names []string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if o.selector != "" {
+		if len(names) > 0 {
+			return clierror.Usage(fmt.Errorf("pass connection names or --selector, not both"))
+		}
+		if names, err = rt.SelectContexts(o.selector); err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return clierror.NotFoundf("no connections match selector %q", o.selector)
+		}
+	} else if len(names) == 0 {
+		return clierror.Usage(fmt.Errorf("pass at least one connection name or --selector"))
+	}
+
+	contexts := make([]*config.Context, 0, len(names))
+	for _, name := range names {
+		if _, ok := rt.Config.GetContext(name); !ok {
+			return clierror.NotFoundf("connection %q not found", name)
+		}
+		ctx, _, err := rt.Config.ResolveContext(name)
+		if err != nil {
+			return clierror.New(clierror.CategoryConfig, err)
+		}
+		contexts = append(contexts, ctx)
+	}
+
+	if o.includeSecrets && !o.yes {
+		if !isInteractive(cmd.InOrStdin()) {
+			return clierror.Usage(fmt.Errorf("--include-secrets requires confirmation; pass --yes when running non-interactively"))
+		}
+		prompt := fmt.Sprintf("The export will contain plaintext secrets for %d connection(s). Type 'yes' to continue", len(contexts))
+		ok, err := confirmPhrase(cmd, bufio.NewReader(cmd.InOrStdin()), prompt, "yes")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return clierror.Newf(clierror.CategoryCancelled, "export cancelled")
+		}
+	}
+
+	data, err := interop.Export(strings.ToLower(strings.TrimSpace(o.format)), contexts, interop.ExportOptions{
+		IncludeSecrets: o.includeSecrets,
+		DBTProfile:     o.dbtProfile,
+	})
+	if err != nil {
+		return clierror.Usage(err)
+	}
+
+	if rt.OutFile != "" {
+		if err := os.WriteFile(rt.OutFile, data, 0o600); err != nil {
+			return fmt.Errorf("write export file: %w", err)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "Output written to %s\n", rt.OutFile)
+		return nil
+	}
+	_, err = cmd.OutOrStdout().Write(data)
+	return err
+}
+
+// confirmPhrase prints prompt to stderr and reports whether the user typed expected.
+func confirmPhrase(cmd *cobra.Command, reader *bufio.Reader, prompt, expected string) (bool, error) {
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s: ", prompt)
+	text, err := reader.ReadString('\n')
+	if err != nil && strings.TrimSpace(text) == "" {
+		return false, nil
+	}
+	return strings.TrimSpace(text) == expected, nil
+}
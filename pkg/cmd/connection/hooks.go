@@ -2,4 +2,9 @@ package connectioncmd
 
 import "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
 
-var testConnectionFn = snowflake.TestConnection
+var (
+	testConnectionFn = snowflake.TestConnection
+	diagnoseFn       = snowflake.Diagnose
+	openSessionFn    = openSession
+	editFn           = runEditor
+)
@@ -0,0 +1,162 @@
+package connectioncmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/interop"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+const (
+	importStatusImported    = "imported"
+	importStatusOverwritten = "overwritten"
+	importStatusConflict    = "conflict"
+	importStatusSkipped     = "skipped"
+)
+
+func newImportConnectionCmd() *cobra.Command {
+	opts := &importOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "import --from snowsql|snowcli [NAME...]",
+		Short: "Import connections from SnowSQL or Snowflake CLI configs",
+		Long: `Import connection profiles from ~/.snowsql/config (--from snowsql) or
+~/.snowflake/connections.toml (--from snowcli). Pass NAME arguments to import a subset.
+Existing snowctl connections are reported as conflicts unless --overwrite is set.`,
+		Example: `snowctl connection import --from snowsql --dry-run
+snowctl connection import --from snowcli --file ./connections.toml --overwrite prod`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&opts.from, "from", "", "Source format: snowsql or snowcli")
+	cmd.Flags().StringVar(&opts.file, "file", "", "Path to the source config (defaults to the tool's standard location)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Report what would be imported without saving")
+	cmd.Flags().BoolVar(&opts.overwrite, "overwrite", false, "Replace existing connections with the same name")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.RegisterFlagCompletionFunc("from", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return interop.Sources, cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+type importOptions struct {
+	from      string
+	file      string
+	dryRun    bool
+	overwrite bool
+}
+
+type importResult struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Account string `json:"account,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type importResponse struct {
+	From        string         `json:"from"`
+	File        string         `json:"file"`
+	DryRun      bool           `json:"dryRun"`
+	SavedAt     string         `json:"savedAt,omitempty"`
+	Connections []importResult `json:"connections"`
+}
+
+func (o *importOptions) run(cmd *cobra.Command, names []string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	source := strings.ToLower(strings.TrimSpace(o.from))
+	if err := interop.ValidateSource(source); err != nil {
+		return clierror.Usage(err)
+	}
+	file := strings.TrimSpace(o.file)
+	if file == "" {
+		file, err = interop.DefaultPath(source)
+		if err != nil {
+			return clierror.New(clierror.CategoryConfig, err)
+		}
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return clierror.NotFoundf("%s config not found at %s; pass --file PATH", source, file)
+		}
+		return clierror.New(clierror.CategoryConfig, fmt.Errorf("read %s: %w", file, err))
+	}
+	parsed, err := interop.Parse(source, data)
+	if err != nil {
+		return clierror.New(clierror.CategoryConfig, err)
+	}
+
+	wanted := map[string]bool{}
+	for _, n := range names {
+		wanted[n] = true
+	}
+
+	resp := importResponse{From: source, File: file, DryRun: o.dryRun, Connections: []importResult{}}
+	changed := false
+	for _, imported := range parsed {
+		ctx := imported.Context
+		if len(wanted) > 0 && !wanted[ctx.Name] {
+			continue
+		}
+		delete(wanted, ctx.Name)
+
+		result := importResult{Name: ctx.Name, Account: ctx.Account}
+		switch {
+		case imported.Skipped != "":
+			result.Status = importStatusSkipped
+			result.Reason = imported.Skipped
+		case config.ValidateConnectionName(ctx.Name) != nil:
+			result.Status = importStatusSkipped
+			result.Reason = config.ValidateConnectionName(ctx.Name).Error()
+		default:
+			_, exists := rt.Config.GetContext(ctx.Name)
+			switch {
+			case exists && !o.overwrite:
+				result.Status = importStatusConflict
+				result.Reason = "a snowctl connection with this name already exists; pass --overwrite to replace it"
+			case exists:
+				result.Status = importStatusOverwritten
+			default:
+				result.Status = importStatusImported
+			}
+			if result.Status != importStatusConflict {
+				if strings.TrimSpace(ctx.Secret) == "" {
+					result.Reason = "no secret found; run 'snowctl connection set " + ctx.Name + "' to store one"
+				}
+				if !o.dryRun {
+					rt.Config.SetContext(ctx.Name, ctx)
+					changed = true
+				}
+			}
+		}
+		resp.Connections = append(resp.Connections, result)
+	}
+	for _, name := range names {
+		if !wanted[name] {
+			continue
+		}
+		resp.Connections = append(resp.Connections, importResult{Name: name, Status: importStatusSkipped, Reason: "not found in " + file})
+	}
+
+	if changed {
+		if err := config.Save(rt.Config); err != nil {
+			return err
+		}
+		resp.SavedAt = connectionsLocation()
+	}
+	return output.Print(cmd, resp)
+}
@@ -1,20 +1,27 @@
 package connectioncmd
 
 import (
+	"slices"
+
 	"github.com/spf13/cobra"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
 )
 
 func newListConnectionsCmd() *cobra.Command {
+	var selector string
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List configured connections",
+		Example: `snowctl connection list -l env=prod
+snowctl connection list -l 'team in (finance,ops),env!=dev'`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runListConnections(cmd)
+			return runListConnections(cmd, selector)
 		},
 	}
+	cmd.Flags().StringVarP(&selector, "selector", "l", "", "Only list connections whose labels match, e.g. env=prod,team in (finance,ops)")
 	return cmd
 }
 
@@ -30,21 +37,59 @@ type connectionView struct {
 	Database    string `json:"database,omitempty"`
 	Schema      string `json:"schema,omitempty"`
 	Description string `json:"description,omitempty"`
+	Extends     string `json:"extends,omitempty"`
+	Host        string `json:"host,omitempty"`
+	Port        int    `json:"port,omitempty"`
+	Protocol    string `json:"protocol,omitempty"`
+	// Proxy is shown with its password redacted.
+	Proxy        string `json:"proxy,omitempty"`
+	NoProxy      string `json:"noProxy,omitempty"`
+	LoginTimeout int    `json:"loginTimeout,omitempty"`
+	OCSPFailOpen *bool  `json:"ocspFailOpen,omitempty"`
+	// Params are the effective session parameters.
+	Params    map[string]string `json:"params,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Protected bool              `json:"protected,omitempty"`
+	ReadOnly  bool              `json:"readOnly,omitempty"`
+	// Inherited maps each setting resolved through extends to the connection
+	// that supplied it; the values above are always the effective ones.
+	Inherited map[string]string `json:"inherited,omitempty"`
+	// Sources maps each setting to the file it came from; only populated when
+	// a project .snowctl.toml is layered over the user config.
+	Sources map[string]string `json:"sources,omitempty"`
+	// Error explains why the connection cannot be resolved, e.g. a broken
+	// extends chain. The values above are then the connection's own.
+	Error string `json:"error,omitempty"`
 }
 
-func runListConnections(cmd *cobra.Command) error {
+// listedSettings are the config keys reported in connectionView.Sources.
+var listedSettings = []string{"account", "accountUrl", "user", "role", "warehouse", "database", "schema", "description",
+	"host", "port", "protocol", "proxy", "noProxy", "loginTimeout", "ocspFailOpen", "params", "labels", "protected", "readOnly"}
+
+func runListConnections(cmd *cobra.Command, selector string) error {
 	rt, err := runtime.RequireRuntime(cmd.Context())
 	if err != nil {
 		return err
 	}
+	selected, err := rt.SelectContexts(selector)
+	if err != nil {
+		return err
+	}
 
+	layered := len(rt.Config.Layers()) > 1
 	contexts := rt.Config.SortedContexts()
 	views := make([]connectionView, 0, len(contexts))
-	for _, ctx := range contexts {
-		if ctx == nil {
+	for _, raw := range contexts {
+		if raw == nil || !slices.Contains(selected, raw.Name) {
 			continue
 		}
-		views = append(views, connectionView{
+		// A broken connection is still listed, with its own settings and the
+		// error, so the others stay visible and it can be found and fixed.
+		ctx, inherited, resolveErr := rt.Config.ResolveContext(raw.Name)
+		if resolveErr != nil {
+			ctx, inherited = raw, nil
+		}
+		view := connectionView{
 			Name:        ctx.Name,
 			IsCurrent:   ctx.Name == rt.Config.CurrentContext,
 			IsDefault:   ctx.Name == rt.Config.DefaultContext,
@@ -56,8 +101,65 @@ func runListConnections(cmd *cobra.Command) error {
 			Database:    ctx.Database,
 			Schema:      ctx.Schema,
 			Description: ctx.Description,
-		})
+			Extends:     ctx.Extends,
+			Params:      ctx.Params,
+			Labels:      ctx.Labels,
+			Protected:   ctx.IsProtected(),
+			ReadOnly:    ctx.IsReadOnly(),
+			Host:        ctx.Host,
+			Port:        ctx.Port,
+			Protocol:    ctx.Protocol,
+			NoProxy:     ctx.NoProxy,
+
+			LoginTimeout: ctx.LoginTimeout,
+			OCSPFailOpen: ctx.OCSPFailOpen,
+		}
+		if ctx.Proxy != "" {
+			view.Proxy = config.RedactProxy(ctx.Proxy)
+		}
+		view.Inherited = inheritedSettings(inherited)
+		if layered {
+			view.Sources = settingSources(rt.Config, ctx.Name)
+		}
+		if resolveErr != nil {
+			view.Error = resolveErr.Error()
+		}
+		views = append(views, view)
 	}
 
 	return output.Print(cmd, views)
 }
+
+// inheritedSettings narrows the inherited map to the settings list shows.
+func inheritedSettings(inherited map[string]string) map[string]string {
+	res := map[string]string{}
+	for _, key := range listedSettings {
+		if from, ok := inherited[key]; ok {
+			res[key] = from
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return res
+}
+
+func settingSources(cfg *config.Config, name string) map[string]string {
+	sources := map[string]string{}
+	for _, key := range listedSettings {
+		if src := cfg.Source("contexts", name, key); src != "" {
+			sources[key] = src
+		}
+	}
+	if name == cfg.CurrentContext {
+		if src := cfg.Source("currentContext"); src != "" {
+			sources["isCurrent"] = src
+		}
+	}
+	if name == cfg.DefaultContext {
+		if src := cfg.Source("defaultContext"); src != "" {
+			sources["isDefault"] = src
+		}
+	}
+	return sources
+}
@@ -1,10 +1,11 @@
 package connectioncmd
 
 import (
-	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
@@ -29,7 +30,10 @@ func runRemoveConnection(cmd *cobra.Command, name string) error {
 		return err
 	}
 	if _, ok := rt.Config.GetContext(name); !ok {
-		return fmt.Errorf("connection %q not found", name)
+		return clierror.NotFoundf("connection %q not found", name)
+	}
+	if children := rt.Config.Children(name); len(children) > 0 {
+		return clierror.Configf("connection %q is extended by %s; remove them or point their extends elsewhere first", name, strings.Join(children, ", "))
 	}
 	rt.Config.DeleteContext(name)
 	if err := config.Save(rt.Config); err != nil {
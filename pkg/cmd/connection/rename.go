@@ -0,0 +1,62 @@
+package connectioncmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func newRenameConnectionCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rename OLD NEW",
+		Aliases: []string{"mv"},
+		Short:   "Rename a saved connection",
+		Long: `Rename a saved connection, keeping its secret. The current and default connection
+pointers and the extends of derived connections are updated to the new name.`,
+		Example: `snowctl connection rename analytics analytics-prod`,
+		Args:    cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRenameConnection(cmd, strings.TrimSpace(args[0]), strings.TrimSpace(args[1]))
+		},
+	}
+	return cmd
+}
+
+func runRenameConnection(cmd *cobra.Command, oldName, newName string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+	children, err := rt.Config.RenameContext(oldName, newName)
+	if err != nil {
+		return connectionNameError(err)
+	}
+	if err := config.Save(rt.Config); err != nil {
+		return err
+	}
+
+	resp := map[string]any{
+		"connection":  newName,
+		"renamedFrom": oldName,
+		"isCurrent":   rt.Config.CurrentContext == newName,
+		"isDefault":   rt.Config.DefaultContext == newName,
+	}
+	if len(children) > 0 {
+		resp["updatedExtends"] = children
+	}
+	return output.Print(cmd, resp)
+}
+
+// connectionNameError classifies errors from renaming or copying connections.
+func connectionNameError(err error) error {
+	if errors.Is(err, config.ErrNotFound) {
+		return clierror.New(clierror.CategoryNotFound, err)
+	}
+	return clierror.Usage(err)
+}
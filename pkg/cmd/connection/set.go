@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
@@ -47,6 +49,12 @@ Secrets such as passwords or PATs are stored with each connection, so you don't
 	cmd.Flags().BoolVar(&opts.makeCurrent, "make-current", false, "Switch to this connection after saving")
 	cmd.Flags().BoolVar(&opts.noPrompt, "no-prompt", false, "Disable interactive prompts; requires all flags to be set")
 	cmd.Flags().StringVar(&opts.secret, "secret", "", "Secret credential (password or PAT) to store with the connection")
+	opts.network.addFlags(cmd)
+	cmd.Flags().StringArrayVar(&opts.params, "param", nil, "Session parameter KEY=VALUE applied on every session (repeatable; KEY= removes it)")
+	cmd.Flags().BoolVar(&opts.readOnly, "read-only", false, "Refuse statements other than reads on this connection")
+	cmd.Flags().BoolVar(&opts.protected, "protected", false, "Require typing the connection name before sql runs mutating statements")
+	cmd.Flags().StringArrayVar(&opts.labels, "label", nil, "Label key=value for selecting connections with -l (repeatable; key= removes it)")
+	cmd.Flags().StringVar(&opts.from, "from", "", "Extend connection BASE; settings left unset are inherited from it (pass \"\" to detach)")
 
 	return cmd
 }
@@ -64,6 +72,12 @@ type setConnectionOptions struct {
 	makeCurrent bool
 	noPrompt    bool
 	secret      string
+	from        string
+	params      []string
+	labels      []string
+	protected   bool
+	readOnly    bool
+	network     networkOptions
 }
 
 func (o *setConnectionOptions) run(cmd *cobra.Command, args []string) error {
@@ -93,6 +107,26 @@ func (o *setConnectionOptions) run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	if cmd.Flags().Changed("from") {
+		base := strings.TrimSpace(o.from)
+		if base != "" {
+			if _, ok := rt.Config.GetContext(base); !ok {
+				return clierror.NotFoundf("base connection %q not found", base)
+			}
+		}
+		ctx.Extends = base
+	}
+	// Prompts and validation work on effective values; fields that match the
+	// parent are stripped again before saving so they keep inheriting.
+	var parent *config.Context
+	if ctx.Extends != "" {
+		parent, _, err = rt.Config.ResolveContext(ctx.Extends)
+		if err != nil {
+			return clierror.New(clierror.CategoryConfig, err)
+		}
+		ctx = ctx.Inherit(parent)
+	}
+
 	envDefaults := map[string]string{
 		"account":     os.Getenv("SNOWFLAKE_ACCOUNT"),
 		"account-url": os.Getenv("SNOWFLAKE_ACCOUNT_URL"),
@@ -115,36 +149,42 @@ func (o *setConnectionOptions) run(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	ctx.Role, err = o.valueOrPrompt(cmd, reader, "Default role", ctx.Role, o.role, "role", envDefaults["role"], true, interactive)
-	if err != nil {
-		return err
-	}
-	ctx.Warehouse, err = o.valueOrPrompt(cmd, reader, "Default warehouse", ctx.Warehouse, o.warehouse, "warehouse", envDefaults["warehouse"], true, interactive)
+	ctx.Description, err = o.valueOrPrompt(cmd, reader, "Description", ctx.Description, o.description, "description", "", false, interactive)
 	if err != nil {
 		return err
 	}
-	ctx.Database, err = o.valueOrPrompt(cmd, reader, "Default database", ctx.Database, o.database, "database", envDefaults["database"], true, interactive)
-	if err != nil {
+
+	if err := o.network.apply(cmd, reader, ctx, interactive); err != nil {
 		return err
 	}
-	ctx.Schema, err = o.valueOrPrompt(cmd, reader, "Default schema", ctx.Schema, o.schema, "schema", envDefaults["schema"], true, interactive)
+
+	ctx.AuthMethod, err = o.resolveAuthMethod(cmd, reader, ctx.AuthMethod, interactive)
 	if err != nil {
 		return err
 	}
-	ctx.Description, err = o.valueOrPrompt(cmd, reader, "Description", ctx.Description, o.description, "description", "", false, interactive)
+	secret, err := o.resolveAuthSecret(cmd, ctx.AuthMethod, ctx.Secret, interactive)
 	if err != nil {
 		return err
 	}
+	ctx.Secret = secret
 
-	ctx.AuthMethod, err = o.resolveAuthMethod(cmd, reader, ctx.AuthMethod, interactive)
+	ctx.Params, err = config.ApplyParams(ctx.Params, o.params)
 	if err != nil {
-		return err
+		return clierror.Usage(err)
 	}
-	secret, err := o.resolveAuthSecret(cmd, ctx.AuthMethod, ctx.Secret, interactive)
+	ctx.Labels, err = config.ApplyLabels(ctx.Labels, o.labels)
 	if err != nil {
+		return clierror.Usage(err)
+	}
+	if cmd.Flags().Changed("protected") {
+		ctx.Protected = &o.protected
+	}
+	if cmd.Flags().Changed("read-only") {
+		ctx.ReadOnly = &o.readOnly
+	}
+	if err := o.resolveObjects(cmd, reader, ctx, envDefaults, interactive); err != nil {
 		return err
 	}
-	ctx.Secret = secret
 
 	name := providedName
 	if name == "" {
@@ -161,6 +201,12 @@ func (o *setConnectionOptions) run(cmd *cobra.Command, args []string) error {
 	if err := config.ValidateConnectionName(name); err != nil {
 		return err
 	}
+	if ctx.Extends != "" {
+		ancestors, _ := rt.Config.Ancestors(ctx.Extends)
+		if ctx.Extends == name || slices.Contains(ancestors, name) {
+			return clierror.Configf("connection %q cannot extend %q: extends cycle", name, ctx.Extends)
+		}
+	}
 
 	ctx.Name = name
 	ts, err := testConnectionFn(cmd.Context(), ctx)
@@ -168,7 +214,9 @@ func (o *setConnectionOptions) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("connection validation failed: %w", err)
 	}
 
-	rt.Config.SetContext(name, ctx)
+	stored := *ctx
+	config.StripInherited(&stored, parent)
+	rt.Config.SetContext(name, &stored)
 	if o.makeCurrent {
 		rt.Config.CurrentContext = name
 	}
@@ -183,6 +231,9 @@ func (o *setConnectionOptions) run(cmd *cobra.Command, args []string) error {
 		"serverTime": ts,
 		"activated":  o.makeCurrent,
 	}
+	if ctx.Extends != "" {
+		resp["extends"] = ctx.Extends
+	}
 	return output.Print(cmd, resp)
 }
 
@@ -271,6 +322,9 @@ func (o *setConnectionOptions) resolveAuthSecret(cmd *cobra.Command, method, cur
 	return "", fmt.Errorf("secret is required; provide --secret when running non-interactively")
 }
 
+// promptString asks for label until it gets an answer, falling back to
+// defaultValue on an empty one. A required prompt with no default fails with
+// io.ErrUnexpectedEOF when the input ends, rather than asking forever.
 func promptString(cmd *cobra.Command, reader *bufio.Reader, label, defaultValue string, required bool) (string, error) {
 	for {
 		prompt := label
@@ -283,6 +337,9 @@ func promptString(cmd *cobra.Command, reader *bufio.Reader, label, defaultValue
 			return "", err
 		}
 		if err != nil && errors.Is(err, io.EOF) && text == "" {
+			if required && defaultValue == "" {
+				return "", fmt.Errorf("%s is required: %w", label, io.ErrUnexpectedEOF)
+			}
 			text = defaultValue
 		}
 		value := strings.TrimSpace(text)
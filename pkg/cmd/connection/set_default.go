@@ -1,10 +1,9 @@
 package connectioncmd
 
 import (
-	"fmt"
-
 	"github.com/spf13/cobra"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
@@ -28,7 +27,7 @@ func runSetDefaultConnection(cmd *cobra.Command, name string) error {
 		return err
 	}
 	if _, ok := rt.Config.GetContext(name); !ok {
-		return fmt.Errorf("connection %q not found", name)
+		return clierror.NotFoundf("connection %q not found", name)
 	}
 	rt.Config.DefaultContext = name
 	if rt.Config.CurrentContext == "" {
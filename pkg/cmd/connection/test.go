@@ -5,32 +5,71 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/style"
 )
 
 func newTestConnectionCmd() *cobra.Command {
 	opts := &testOptions{}
 
 	cmd := &cobra.Command{
-		Use:   "test [NAME]",
+		Use:   "test [NAME | --all [-l SELECTOR]]",
 		Short: "Test connectivity for a stored connection",
-		Args:  cobra.MaximumNArgs(1),
+		Example: `snowctl connection test prod
+snowctl connection test prod --verbose -o table
+snowctl connection test --all --parallel 8 -o table
+snowctl connection test -l env=prod -o csv`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return opts.run(cmd, args)
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.setCurrent, "set-current", false, "Set this connection as current after a successful test")
+	cmd.Flags().BoolVar(&opts.verbose, "verbose", false, "Report each step (DNS, TCP/TLS, login, role, warehouse, database, schema) with timings")
+	cmd.Flags().BoolVar(&opts.all, "all", false, "Test every stored connection")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "Test the connections whose labels match (implies --all), e.g. env=prod")
+	cmd.Flags().IntVar(&opts.parallel, "parallel", 4, "Maximum number of connections tested at once with --all/--selector")
 	return cmd
 }
 
 type testOptions struct {
 	setCurrent bool
+	verbose    bool
+	all        bool
+	selector   string
+	parallel   int
+}
+
+// testResult is one row of a multi-connection test. LatencyMS is the time
+// spent logging in and reading the server time, whether or not it succeeded.
+type testResult struct {
+	Connection string `json:"connection" yaml:"connection"`
+	Status     string `json:"status" yaml:"status"`
+	LatencyMS  int64  `json:"latencyMs" yaml:"latencyMs"`
+	ServerTime string `json:"serverTime,omitempty" yaml:"serverTime,omitempty"`
+	Category   string `json:"category,omitempty" yaml:"category,omitempty"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// testReport orders the columns of tabular output.
+type testReport []testResult
+
+func (testReport) OutputColumns() []format.Column {
+	return []format.Column{
+		{Name: "connection"}, {Name: "status"}, {Name: "latencyMs"},
+		{Name: "serverTime"}, {Name: "category"}, {Name: "error"},
+	}
 }
 
 func (o *testOptions) run(cmd *cobra.Command, args []string) error {
@@ -39,6 +78,22 @@ func (o *testOptions) run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if o.all || o.selector != "" {
+		if len(args) > 0 {
+			return clierror.Usage(fmt.Errorf("pass a connection name or --all/--selector, not both"))
+		}
+		if o.setCurrent {
+			return clierror.Usage(fmt.Errorf("--set-current needs a single connection"))
+		}
+		if o.verbose {
+			return clierror.Usage(fmt.Errorf("--verbose needs a single connection"))
+		}
+		if o.parallel < 1 {
+			return clierror.Usage(fmt.Errorf("--parallel must be at least 1"))
+		}
+		return o.runAll(cmd, rt)
+	}
+
 	name := ""
 	if len(args) > 0 {
 		name = args[0]
@@ -49,7 +104,7 @@ func (o *testOptions) run(cmd *cobra.Command, args []string) error {
 	if name == "" {
 		contexts := rt.Config.SortedContexts()
 		if len(contexts) == 0 {
-			return fmt.Errorf("no connections configured. Use 'snowctl connection set' first")
+			return clierror.Configf("no connections configured. Use 'snowctl connection set' first")
 		}
 		if len(contexts) == 1 {
 			connection = contexts[0]
@@ -63,15 +118,21 @@ func (o *testOptions) run(cmd *cobra.Command, args []string) error {
 			name = connection.Name
 		}
 	} else {
-		ctx, ok := rt.Config.GetContext(name)
-		if !ok {
-			return fmt.Errorf("connection %q not found", name)
+		if _, ok := rt.Config.GetContext(name); !ok {
+			return clierror.NotFoundf("connection %q not found", name)
 		}
-		connection = ctx
+	}
+	connection, err = rt.ResolveContext(name)
+	if err != nil {
+		return clierror.New(clierror.CategoryConfig, err)
 	}
 
 	if strings.TrimSpace(connection.Secret) == "" {
-		return fmt.Errorf("connection %q has no stored credential. Re-run 'snowctl connection set %s' to store one.", name, name)
+		return clierror.Configf("connection %q has no stored credential. Re-run 'snowctl connection set %s' to store one.", name, name)
+	}
+
+	if o.verbose {
+		return o.runVerbose(cmd, rt, name, connection)
 	}
 
 	ts, err := testConnectionFn(cmd.Context(), connection)
@@ -98,12 +159,162 @@ func (o *testOptions) run(cmd *cobra.Command, args []string) error {
 	return output.Print(cmd, resp)
 }
 
+// diagnosisResponse is the report of connection test --verbose. Tabular
+// formats print the stages as rows with the rest as metadata.
+type diagnosisResponse struct {
+	Connection    string            `json:"connection" yaml:"connection"`
+	Account       string            `json:"account" yaml:"account"`
+	User          string            `json:"user" yaml:"user"`
+	Host          string            `json:"host" yaml:"host"`
+	Port          int               `json:"port" yaml:"port"`
+	ServerVersion string            `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
+	DriverVersion string            `json:"driverVersion" yaml:"driverVersion"`
+	ServerTime    string            `json:"serverTime,omitempty" yaml:"serverTime,omitempty"`
+	CurrentSet    bool              `json:"currentSet" yaml:"currentSet"`
+	Stages        []snowflake.Stage `json:"stages,omitempty" yaml:"stages,omitempty"`
+}
+
+func (r diagnosisResponse) OutputMetadata() (interface{}, interface{}) {
+	meta := r
+	meta.Stages = nil
+	return meta, r.Stages
+}
+
+func (diagnosisResponse) OutputColumns() []format.Column {
+	return []format.Column{
+		{Name: "stage"}, {Name: "status"}, {Name: "durationMs"},
+		{Name: "detail"}, {Name: "category"}, {Name: "error"},
+	}
+}
+
+// runVerbose diagnoses the connection stage by stage and prints the report
+// even when a stage fails, so the failing step is visible.
+func (o *testOptions) runVerbose(cmd *cobra.Command, rt *runtime.Runtime, name string, connection *config.Context) error {
+	d, diagErr := diagnoseFn(cmd.Context(), connection)
+	if d == nil {
+		return diagErr
+	}
+	resp := diagnosisResponse{
+		Connection:    name,
+		Account:       connection.Account,
+		User:          connection.User,
+		Host:          d.Host,
+		Port:          d.Port,
+		ServerVersion: d.ServerVersion,
+		DriverVersion: d.DriverVersion,
+		ServerTime:    d.ServerTime,
+		Stages:        d.Stages,
+	}
+	if diagErr == nil && o.setCurrent {
+		rt.Config.CurrentContext = name
+		if err := config.Save(rt.Config); err != nil {
+			return fmt.Errorf("failed to update current connection: %w", err)
+		}
+		resp.CurrentSet = true
+	}
+	if err := output.Print(cmd, resp); err != nil {
+		return err
+	}
+	if diagErr != nil {
+		return fmt.Errorf("connection failed at %w", diagErr)
+	}
+	return nil
+}
+
+// runAll tests every connection matching the selector, at most o.parallel
+// at a time, and fails when any of them does, with the exit code of the first
+// failure in name order.
+func (o *testOptions) runAll(cmd *cobra.Command, rt *runtime.Runtime) error {
+	names, err := rt.SelectContexts(o.selector)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		if o.selector != "" {
+			return clierror.NotFoundf("no connections match selector %q", o.selector)
+		}
+		return clierror.Configf("no connections configured. Use 'snowctl connection set' first")
+	}
+
+	results := make(testReport, len(names))
+	errs := make([]error, len(names))
+	// Resolve up front: the config is not safe for concurrent use, only the
+	// logins run in parallel.
+	connections := make([]*config.Context, len(names))
+	for i, name := range names {
+		results[i] = testResult{Connection: name, Status: "ok"}
+		connections[i], errs[i] = resolveForTest(rt, name)
+	}
+
+	sem := make(chan struct{}, o.parallel)
+	var wg sync.WaitGroup
+	for i, connection := range connections {
+		if errs[i] != nil {
+			continue
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			start := time.Now()
+			results[i].ServerTime, errs[i] = testConnectionFn(cmd.Context(), connection)
+			results[i].LatencyMS = time.Since(start).Milliseconds()
+		}()
+	}
+	wg.Wait()
+
+	var firstFailure *clierror.Error
+	failed := 0
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		classified := clierror.Classify(err)
+		if firstFailure == nil {
+			firstFailure = classified
+		}
+		failed++
+		results[i].Status = "failed"
+		results[i].ServerTime = ""
+		results[i].Category = string(classified.Category)
+		results[i].Error = err.Error()
+	}
+
+	if err := output.Print(cmd, results); err != nil {
+		return err
+	}
+	if firstFailure != nil {
+		return clierror.New(firstFailure.Category, fmt.Errorf("%d of %d connection(s) failed", failed, len(names)))
+	}
+	return nil
+}
+
+// resolveForTest resolves a stored connection and checks it has a credential
+// to log in with.
+func resolveForTest(rt *runtime.Runtime, name string) (*config.Context, error) {
+	connection, err := rt.ResolveContext(name)
+	if err != nil {
+		return nil, clierror.New(clierror.CategoryConfig, err)
+	}
+	if strings.TrimSpace(connection.Secret) == "" {
+		return nil, clierror.Configf("connection %q has no stored credential", name)
+	}
+	return connection, nil
+}
+
 func promptConnectionSelection(cmd *cobra.Command, contexts []*config.Context) (*config.Context, error) {
-	fmt.Fprintln(cmd.OutOrStdout(), "Select a connection:")
+	out := cmd.OutOrStdout()
+	styler := output.Styler(cmd, out)
+	var current, def string
+	if rt, ok := runtime.FromContext(cmd.Context()); ok && rt.Config != nil {
+		current, def = rt.Config.CurrentContext, rt.Config.DefaultContext
+	}
+
+	fmt.Fprintln(out, styler.Bold("Select a connection:"))
 	for i, ctx := range contexts {
-		fmt.Fprintf(cmd.OutOrStdout(), "  %d) %s\n", i+1, ctx.Name)
+		fmt.Fprintf(out, "  %d) %s\n", i+1, connectionLabel(styler, ctx.Name, current, def))
 	}
-	fmt.Fprintf(cmd.OutOrStdout(), "Enter number or name [%s]: ", contexts[0].Name)
+	fmt.Fprintf(out, "Enter number or name [%s]: ", contexts[0].Name)
 
 	reader := bufio.NewReader(cmd.InOrStdin())
 	input, err := reader.ReadString('\n')
@@ -128,5 +339,24 @@ func promptConnectionSelection(cmd *cobra.Command, contexts []*config.Context) (
 		}
 	}
 
-	return nil, fmt.Errorf("connection %q not found", choice)
+	return nil, clierror.NotFoundf("connection %q not found", choice)
+}
+
+// connectionLabel decorates a connection name with current/default markers.
+func connectionLabel(styler style.Styler, name, current, def string) string {
+	var tags []string
+	if name == current {
+		tags = append(tags, "current")
+	}
+	if name == def {
+		tags = append(tags, "default")
+	}
+	if len(tags) == 0 {
+		return name
+	}
+	label := fmt.Sprintf("%s (%s)", name, strings.Join(tags, ", "))
+	if name == current {
+		return styler.Success("* " + label)
+	}
+	return styler.Accent(label)
 }
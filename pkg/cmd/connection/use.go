@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
@@ -35,9 +36,12 @@ func runUseConnection(cmd *cobra.Command, name string) error {
 	if err != nil {
 		return err
 	}
-	ctx, ok := rt.Config.GetContext(name)
-	if !ok {
-		return fmt.Errorf("connection %q not found", name)
+	if _, ok := rt.Config.GetContext(name); !ok {
+		return clierror.NotFoundf("connection %q not found", name)
+	}
+	ctx, _, err := rt.Config.ResolveContext(name)
+	if err != nil {
+		return clierror.New(clierror.CategoryConfig, err)
 	}
 	rt.Config.CurrentContext = name
 	if err := config.Save(rt.Config); err != nil {
@@ -0,0 +1,177 @@
+package doctorcmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+const (
+	statusPass = "pass"
+	statusWarn = "warn"
+	statusFail = "fail"
+)
+
+type check struct {
+	Check      string `json:"check" yaml:"check"`
+	Connection string `json:"connection,omitempty" yaml:"connection,omitempty"`
+	Status     string `json:"status" yaml:"status"`
+	Message    string `json:"message" yaml:"message"`
+	Fix        string `json:"fix,omitempty" yaml:"fix,omitempty"`
+}
+
+type doctor struct {
+	rt     *runtime.Runtime
+	path   string
+	checks []check
+}
+
+func (d *doctor) add(name, connection, status, message, fix string) {
+	d.checks = append(d.checks, check{Check: name, Connection: connection, Status: status, Message: message, Fix: fix})
+}
+
+// checkConfigFile verifies that the config loads and that only the owner can read it.
+func (d *doctor) checkConfigFile() {
+	path, err := config.Path()
+	if err != nil {
+		d.add("config.path", "", statusFail, err.Error(), "Set HOME or pass --config PATH")
+		return
+	}
+	d.path = path
+
+	if d.rt.ConfigError != nil {
+		d.add("config.load", "", statusFail, d.rt.ConfigError.Error(), fmt.Sprintf("Edit %s to fix the reported entry, then re-run 'snowctl doctor'", path))
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		d.add("config.file", "", statusWarn, fmt.Sprintf("%s does not exist", path), "Create a connection with 'snowctl connection set NAME'")
+		return
+	}
+	if err != nil {
+		d.add("config.file", "", statusFail, err.Error(), "")
+		return
+	}
+	if d.rt.ConfigError == nil {
+		d.add("config.load", "", statusPass, fmt.Sprintf("%s parsed", path), "")
+	}
+
+	if goruntime.GOOS == "windows" {
+		return
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		d.add("config.permissions", "", statusFail, fmt.Sprintf("%s is mode %04o; secrets are readable by other users", path, perm), fmt.Sprintf("chmod 600 %s", path))
+	} else {
+		d.add("config.permissions", "", statusPass, fmt.Sprintf("%s is mode %04o", path, perm), "")
+	}
+	dir := filepath.Dir(path)
+	if dirInfo, err := os.Stat(dir); err == nil && dirInfo.Mode().Perm()&0o077 != 0 {
+		d.add("config.directory", "", statusWarn, fmt.Sprintf("%s is mode %04o", dir, dirInfo.Mode().Perm()), fmt.Sprintf("chmod 700 %s", dir))
+	}
+
+	for _, layer := range d.rt.Config.Layers() {
+		if layer.Kind != config.LayerProject {
+			continue
+		}
+		for _, name := range d.rt.Config.ContextNames() {
+			if d.rt.Config.Source("contexts", name, "secret") == layer.Path {
+				d.add("config.project", name, statusWarn, fmt.Sprintf("%s stores a secret and may be committed to version control", layer.Path),
+					fmt.Sprintf("Move the secret to %s with 'snowctl config set contexts.%s.secret ...'", path, name))
+			}
+		}
+	}
+}
+
+// checkPointers verifies currentContext and defaultContext.
+func (d *doctor) checkPointers() {
+	cfg := d.rt.Config
+	if len(cfg.Contexts) == 0 {
+		d.add("connections", "", statusWarn, "no connections configured", "Create one with 'snowctl connection set NAME' or 'snowctl connection import'")
+		return
+	}
+	for _, ptr := range []struct{ key, value, fix string }{
+		{"currentContext", cfg.CurrentContext, "snowctl connection use NAME"},
+		{"defaultContext", cfg.DefaultContext, "snowctl connection set-default NAME"},
+	} {
+		switch _, ok := cfg.GetContext(ptr.value); {
+		case ptr.value == "":
+			d.add("config."+ptr.key, "", statusWarn, ptr.key+" is not set", ptr.fix)
+		case !ok:
+			d.add("config."+ptr.key, ptr.value, statusFail, fmt.Sprintf("%s points at missing connection %q", ptr.key, ptr.value), ptr.fix)
+		default:
+			d.add("config."+ptr.key, ptr.value, statusPass, fmt.Sprintf("%s is %q", ptr.key, ptr.value), "")
+		}
+	}
+}
+
+// connections returns the requested names, or every configured connection.
+func (d *doctor) connections(names []string) []string {
+	if len(names) > 0 {
+		return names
+	}
+	return d.rt.Config.ContextNames()
+}
+
+// checkConnection runs the offline checks for one connection.
+func (d *doctor) checkConnection(name string) {
+	if _, ok := d.rt.Config.GetContext(name); !ok {
+		d.add("connection.exists", name, statusFail, fmt.Sprintf("connection %q not found", name), "Run 'snowctl connection list' to see configured names")
+		return
+	}
+	ctx, err := d.rt.ResolveContext(name)
+	if err != nil {
+		d.add("connection.extends", name, statusFail, err.Error(), fmt.Sprintf("snowctl config unset contexts.%s.extends", name))
+		return
+	}
+	setFix := fmt.Sprintf("snowctl connection set %s", name)
+
+	if err := config.ValidateAccountIdentifier(ctx.Account); err != nil {
+		d.add("connection.account", name, statusFail, err.Error(), fmt.Sprintf("snowctl config set contexts.%s.account ORG-ACCOUNT", name))
+	} else {
+		d.add("connection.account", name, statusPass, fmt.Sprintf("account %q is well-formed", ctx.Account), "")
+	}
+
+	switch {
+	case ctx.AuthMethod == "":
+		d.add("connection.auth", name, statusWarn, "authMethod is not set; password is assumed", fmt.Sprintf("snowctl config set contexts.%s.authMethod password", name))
+	case config.ValidateAuthMethod(ctx.AuthMethod) != nil:
+		d.add("connection.auth", name, statusFail, config.ValidateAuthMethod(ctx.AuthMethod).Error(), setFix+" --auth-method password|pat")
+	default:
+		d.add("connection.auth", name, statusPass, fmt.Sprintf("auth method %q", ctx.AuthMethod), "")
+	}
+
+	if strings.TrimSpace(ctx.Secret) == "" {
+		d.add("connection.secret", name, statusFail, "no stored password or PAT", setFix)
+	} else {
+		d.add("connection.secret", name, statusPass, "credential stored", "")
+	}
+
+	if err := ctx.ValidateNetwork(); err != nil {
+		d.add("connection.network", name, statusFail, err.Error(), setFix+" --advanced")
+	}
+}
+
+// checkLive logs in with the connection and reports the categorised failure.
+func (d *doctor) checkLive(cmd *cobra.Command, name string) {
+	ctx, err := d.rt.ResolveContext(name)
+	if err != nil || strings.TrimSpace(ctx.Secret) == "" {
+		return
+	}
+	ts, err := testConnectionFn(cmd.Context(), ctx)
+	if err != nil {
+		classified := clierror.Classify(err)
+		d.add("connection.live", name, statusFail, fmt.Sprintf("%s error: %v", classified.Category, err), fmt.Sprintf("snowctl connection test %s", name))
+		return
+	}
+	d.add("connection.live", name, statusPass, fmt.Sprintf("logged in; server time %s", ts), "")
+}
@@ -0,0 +1,111 @@
+package doctorcmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+// NewDoctorCmd diagnoses common configuration and connectivity problems.
+func NewDoctorCmd() *cobra.Command {
+	opts := &doctorOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "doctor [NAME...]",
+		Short: "Diagnose snowctl configuration problems",
+		Long: `Run offline checks on the snowctl configuration: file permissions, current/default
+connection pointers, and each connection's account identifier, auth method, secret, and network
+settings. With --live, each connection is also tested against Snowflake.
+
+Every check reports pass, warn, or fail with a suggested fix. The command exits non-zero when any
+check fails. Pass connection names, or a label selector with -l, to limit the per-connection checks.`,
+		Example: `snowctl doctor
+snowctl doctor prod --live -o yaml
+snowctl doctor -l env=prod --live`,
+		Annotations: map[string]string{runtime.AnnotationAllowConfigError: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return opts.run(cmd, args)
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.live, "live", false, "Also log in to Snowflake with each connection")
+	cmd.Flags().StringVarP(&opts.selector, "selector", "l", "", "Only check connections whose labels match, e.g. env=prod")
+	return cmd
+}
+
+type doctorOptions struct {
+	live     bool
+	selector string
+}
+
+// report is the doctor output: a summary plus one row per check.
+type report struct {
+	Summary summary `json:"summary" yaml:"summary"`
+	Checks  []check `json:"checks" yaml:"checks"`
+}
+
+type summary struct {
+	Config string `json:"config,omitempty" yaml:"config,omitempty"`
+	Pass   int    `json:"pass" yaml:"pass"`
+	Warn   int    `json:"warn" yaml:"warn"`
+	Fail   int    `json:"fail" yaml:"fail"`
+}
+
+// OutputMetadata lets tabular formats print the summary above the checks.
+func (r report) OutputMetadata() (interface{}, interface{}) {
+	return r.Summary, r.Checks
+}
+
+func (o *doctorOptions) run(cmd *cobra.Command, names []string) error {
+	rt, err := runtime.RequireRuntime(cmd.Context())
+	if err != nil {
+		return err
+	}
+
+	if o.selector != "" && len(names) > 0 {
+		return clierror.Usage(fmt.Errorf("pass connection names or --selector, not both"))
+	}
+
+	d := &doctor{rt: rt}
+	d.checkConfigFile()
+	if rt.ConfigError == nil {
+		if o.selector != "" {
+			if names, err = rt.SelectContexts(o.selector); err != nil {
+				return err
+			}
+			if len(names) == 0 {
+				return clierror.NotFoundf("no connections match selector %q", o.selector)
+			}
+		}
+		d.checkPointers()
+		for _, name := range d.connections(names) {
+			d.checkConnection(name)
+			if o.live {
+				d.checkLive(cmd, name)
+			}
+		}
+	}
+
+	res := report{Summary: summary{Config: d.path}, Checks: d.checks}
+	for _, c := range d.checks {
+		switch c.Status {
+		case statusPass:
+			res.Summary.Pass++
+		case statusWarn:
+			res.Summary.Warn++
+		case statusFail:
+			res.Summary.Fail++
+		}
+	}
+	if err := output.Print(cmd, res); err != nil {
+		return err
+	}
+	if res.Summary.Fail > 0 {
+		return clierror.New(clierror.CategoryConfig, fmt.Errorf("doctor found %d failing check(s)", res.Summary.Fail))
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package doctorcmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+func prepareRuntime(t *testing.T, configure func(*config.Config)) (*runtime.Runtime, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+
+	cfg := config.DefaultConfig()
+	if configure != nil {
+		configure(cfg)
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	rt, err := runtime.NewRuntimeWithOptions(runtime.Options{OutputFormat: "json", AllowConfigError: true})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	path, _ := config.Path()
+	return rt, path
+}
+
+func runDoctor(t *testing.T, rt *runtime.Runtime, opts *doctorOptions, names ...string) (report, error) {
+	t.Helper()
+	cmd := &cobra.Command{}
+	buf := &bytes.Buffer{}
+	cmd.SetOut(buf)
+	cmd.SetContext(runtime.WithRuntime(context.Background(), rt))
+	err := opts.run(cmd, names)
+	var res report
+	if decodeErr := json.Unmarshal(buf.Bytes(), &res); decodeErr != nil {
+		t.Fatalf("decode: %v (%s)", decodeErr, buf.String())
+	}
+	return res, err
+}
+
+func statuses(res report) map[string]string {
+	out := map[string]string{}
+	for _, c := range res.Checks {
+		out[c.Check+"/"+c.Connection] = c.Status
+	}
+	return out
+}
+
+func TestDoctorPassesHealthyConfig(t *testing.T) {
+	rt, _ := prepareRuntime(t, func(cfg *config.Config) {
+		cfg.SetContext("prod", &config.Context{Account: "myorg-prod", AuthMethod: "password", Secret: "pw"})
+	})
+
+	res, err := runDoctor(t, rt, &doctorOptions{})
+	if err != nil {
+		t.Fatalf("doctor: %v", err)
+	}
+	if res.Summary.Fail != 0 || res.Summary.Warn != 0 {
+		t.Fatalf("expected a clean report, got %+v", res)
+	}
+	if statuses(res)["config.permissions/"] != statusPass {
+		t.Fatalf("expected 0600 config to pass, got %+v", res.Checks)
+	}
+}
+
+func TestDoctorReportsProblemsWithFixes(t *testing.T) {
+	rt, path := prepareRuntime(t, func(cfg *config.Config) {
+		cfg.SetContext("bad", &config.Context{Account: "xy123.snowflakecomputing.com", AuthMethod: "kerberos"})
+		cfg.SetContext("orphan", &config.Context{Extends: "missing"})
+		cfg.CurrentContext = "gone"
+	})
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+
+	res, err := runDoctor(t, rt, &doctorOptions{})
+	if err == nil {
+		t.Fatalf("expected non-nil error when checks fail")
+	}
+	got := statuses(res)
+	for _, key := range []string{"config.permissions/", "config.currentContext/gone", "connection.account/bad", "connection.auth/bad", "connection.secret/bad", "connection.extends/orphan"} {
+		if got[key] != statusFail {
+			t.Fatalf("expected %s to fail, got %v", key, got)
+		}
+	}
+	for _, c := range res.Checks {
+		if c.Status == statusFail && c.Fix == "" {
+			t.Fatalf("failing check %s has no fix suggestion", c.Check)
+		}
+	}
+}
+
+func TestDoctorRunsWhenConfigFailsToLoad(t *testing.T) {
+	rt, path := prepareRuntime(t, nil)
+	if err := os.WriteFile(path, []byte("[contexts.a\naccount = \"a\"\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	rt, err := runtime.NewRuntimeWithOptions(runtime.Options{OutputFormat: "json", AllowConfigError: true})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+
+	res, err := runDoctor(t, rt, &doctorOptions{})
+	if err == nil || statuses(res)["config.load/"] != statusFail {
+		t.Fatalf("expected config.load failure, got %+v (%v)", res, err)
+	}
+	if filepath.Clean(res.Summary.Config) != filepath.Clean(path) {
+		t.Fatalf("expected config path in summary, got %q", res.Summary.Config)
+	}
+}
+
+func TestDoctorLiveChecks(t *testing.T) {
+	rt, _ := prepareRuntime(t, func(cfg *config.Config) {
+		cfg.SetContext("ok", &config.Context{Account: "myorg-ok", AuthMethod: "password", Secret: "pw"})
+		cfg.SetContext("down", &config.Context{Account: "myorg-down", AuthMethod: "password", Secret: "pw"})
+	})
+	orig := testConnectionFn
+	testConnectionFn = func(ctx context.Context, info *config.Context) (string, error) {
+		if info.Name == "down" {
+			return "", errors.New("dial tcp: connection refused")
+		}
+		return "2025-01-01T00:00:00Z", nil
+	}
+	defer func() { testConnectionFn = orig }()
+
+	res, err := runDoctor(t, rt, &doctorOptions{live: true})
+	if err == nil {
+		t.Fatalf("expected failure from live check")
+	}
+	got := statuses(res)
+	if got["connection.live/ok"] != statusPass || got["connection.live/down"] != statusFail {
+		t.Fatalf("unexpected live results: %v", got)
+	}
+}
@@ -0,0 +1,5 @@
+package doctorcmd
+
+import "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
+
+var testConnectionFn = snowflake.TestConnection
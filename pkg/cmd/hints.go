@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/snowflakedb/gosnowflake"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+)
+
+// hintContext carries what a hint needs to name the active connection and the command that fixes it.
+type hintContext struct {
+	commandPath string
+	connection  string
+	role        string
+	ephemeral   bool
+}
+
+func (h hintContext) connectionName() string {
+	if h.connection == "" {
+		return "NAME"
+	}
+	return h.connection
+}
+
+func (h hintContext) roleName() string {
+	if h.role == "" {
+		return "the active role"
+	}
+	return fmt.Sprintf("role %q", h.role)
+}
+
+// setCommand renders the `connection set` invocation for the active connection.
+func (h hintContext) setCommand(flags string) string {
+	cmd := fmt.Sprintf("%s connection set %s", h.commandPath, h.connectionName())
+	if flags != "" {
+		cmd += " " + flags
+	}
+	return cmd
+}
+
+// settingFix names how to change one connection setting: the `connection set`
+// flag for a stored connection, or the global flag and environment variable
+// for an ephemeral connection, which cannot be saved.
+func (h hintContext) settingFix(flag, env string) string {
+	if h.ephemeral {
+		return fmt.Sprintf("'%s' or %s", flag, env)
+	}
+	return fmt.Sprintf("'%s'", h.setCommand(flag))
+}
+
+func (h hintContext) testCommand() string {
+	return fmt.Sprintf("%s connection test %s", h.commandPath, h.connectionName())
+}
+
+func (h hintContext) subject() string {
+	if h.connection == "" {
+		return "the connection"
+	}
+	return fmt.Sprintf("connection %q", h.connection)
+}
+
+// snowflakeHints maps Snowflake server and gosnowflake driver error numbers to remediation hints.
+var snowflakeHints = map[int]func(h hintContext) string{
+	390100: func(h hintContext) string {
+		return fmt.Sprintf("Incorrect username or password for %s. Update it with '%s' and verify with '%s'.", h.subject(), h.setCommand(""), h.testCommand())
+	},
+	390101: func(h hintContext) string {
+		return fmt.Sprintf("The user for %s is temporarily locked after failed logins. Wait for the lock to expire or ask an administrator to unlock it, then run '%s'.", h.subject(), h.testCommand())
+	},
+	390102: func(h hintContext) string {
+		return fmt.Sprintf("The user for %s is disabled. Ask an administrator to re-enable it or switch connections with '%s connection use NAME'.", h.subject(), h.commandPath)
+	},
+	390114: func(h hintContext) string {
+		return fmt.Sprintf("The session token for %s expired. Re-run the command or verify the credential with '%s'.", h.subject(), h.testCommand())
+	},
+	390144: func(h hintContext) string {
+		return fmt.Sprintf("The token for %s was rejected. Store a fresh credential with '%s'.", h.subject(), h.setCommand("--secret ..."))
+	},
+	390189: func(h hintContext) string {
+		return fmt.Sprintf("%s is not granted to the user of %s or does not exist. Pick another role with '%s'.", capitalize(h.roleName()), h.subject(), h.setCommand("--role ROLE"))
+	},
+	390201: func(h hintContext) string {
+		return fmt.Sprintf("The database, schema, or warehouse configured for %s does not exist or %s cannot use it. Fix it with '%s'.", h.subject(), h.roleName(), h.setCommand(""))
+	},
+	390303: func(h hintContext) string {
+		return fmt.Sprintf("The access token for %s is invalid. Store a new one with '%s'.", h.subject(), h.setCommand("--secret ..."))
+	},
+	390318: func(h hintContext) string {
+		return fmt.Sprintf("The access token for %s has expired. Store a new one with '%s'.", h.subject(), h.setCommand("--secret ..."))
+	},
+	2003: func(h hintContext) string {
+		return fmt.Sprintf("The object does not exist, or %s lacks privileges on it (connection %q). Check the fully qualified name or switch roles with %s.", h.roleName(), h.connectionName(), h.settingFix("--role ROLE", "SNOWFLAKE_ROLE"))
+	},
+	2043: func(h hintContext) string {
+		return fmt.Sprintf("The object does not exist or %s cannot operate on it. Check the name and grants, or switch roles with %s.", h.roleName(), h.settingFix("--role ROLE", "SNOWFLAKE_ROLE"))
+	},
+	606: func(h hintContext) string {
+		return fmt.Sprintf("No active warehouse is selected for %s. Set one with %s.", h.subject(), h.settingFix("--warehouse WAREHOUSE", "SNOWFLAKE_WAREHOUSE"))
+	},
+	604: func(h hintContext) string {
+		return "The statement was cancelled before it finished. Re-run it, or check for a STATEMENT_TIMEOUT_IN_SECONDS limit."
+	},
+	gosnowflake.ErrCodeFailedToConnect: func(h hintContext) string {
+		return fmt.Sprintf("Could not reach the Snowflake account for %s. Check the account identifier with '%s'.", h.subject(), h.setCommand("--account ACCOUNT"))
+	},
+	gosnowflake.ErrCodeEmptyAccountCode: func(h hintContext) string {
+		return fmt.Sprintf("%s has no account configured. Set one with '%s'.", capitalize(h.subject()), h.setCommand("--account ACCOUNT"))
+	},
+	gosnowflake.ErrCodeEmptyUsernameCode: func(h hintContext) string {
+		return fmt.Sprintf("%s has no user configured. Set one with '%s'.", capitalize(h.subject()), h.setCommand("--user USER"))
+	},
+	gosnowflake.ErrCodeEmptyPasswordCode: func(h hintContext) string {
+		return fmt.Sprintf("%s has no stored secret. Store one with '%s'.", capitalize(h.subject()), h.setCommand("--secret ..."))
+	},
+}
+
+// categoryHints are used when no error-number specific hint applies.
+var categoryHints = map[clierror.Category]func(h hintContext) string{
+	clierror.CategoryAuth: func(h hintContext) string {
+		return fmt.Sprintf("Snowflake rejected the credentials for %s. Check them with '%s'.", h.subject(), h.testCommand())
+	},
+	clierror.CategoryNetwork: func(h hintContext) string {
+		return fmt.Sprintf("Snowflake could not be reached for %s. Check network access, proxy settings, and the account identifier, then run '%s'.", h.subject(), h.testCommand())
+	},
+}
+
+func hintForError(err error, commandPath string, rt *runtime.Runtime) string {
+	msg := err.Error()
+	if unknownCmd, ok := parseUnknownCommand(msg); ok {
+		if unknownCmd == "version" {
+			return fmt.Sprintf("Use '%s --version' to print the CLI version.", commandPath)
+		}
+		return fmt.Sprintf("Run '%s --help' to see available commands.", commandPath)
+	}
+
+	h := hintContext{commandPath: commandPath}
+	if rt != nil {
+		h.connection = rt.ActiveContextName
+		h.ephemeral = rt.Ephemeral
+		if rt.ActiveContext != nil {
+			h.role = rt.ActiveContext.Role
+		}
+	}
+
+	classified := clierror.Classify(err)
+	hint, ok := snowflakeHints[classified.Number]
+	if !ok || classified.Number == 0 {
+		hint, ok = categoryHints[classified.Category]
+	}
+	if !ok {
+		return ""
+	}
+	if h.ephemeral && connectionCategories[classified.Category] {
+		// There is no stored connection to fix with 'connection set' or
+		// check with 'connection test'.
+		return ephemeralHint
+	}
+	return hint(h)
+}
+
+// connectionCategories are the error categories whose hints point at the
+// stored connection settings rather than the statement or the command line.
+var connectionCategories = map[clierror.Category]bool{
+	clierror.CategoryAuth:    true,
+	clierror.CategoryConfig:  true,
+	clierror.CategoryNetwork: true,
+}
+
+const ephemeralHint = "The connection was built from flags and environment variables. Check --account, --user, --role, --warehouse, and --auth-method, and the SNOWCTL_*/SNOWFLAKE_* variables (the secret comes from SNOWCTL_SECRET or SNOWFLAKE_PASSWORD)."
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
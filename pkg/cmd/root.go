@@ -1,24 +1,36 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/build"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	configcmd "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/cmd/config"
 	connectioncmd "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/cmd/connection"
+	doctorcmd "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/cmd/doctor"
 	showcmd "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/cmd/show"
 	sqlcmd "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/cmd/sql"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/style"
 )
 
 var (
 	connectionOverride string
 	outputFormat       string
+	outFile            string
+	noColor            bool
+	configPath         string
+	sessionParams      []string
+	ephemeral          runtime.EphemeralFlags
 )
 
 // NewRootCmd constructs the root snowctl command with global flags and subcommands.
@@ -30,7 +42,17 @@ func NewRootCmd() *cobra.Command {
 		SilenceErrors: true,
 		SilenceUsage:  true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			rt, err := runtime.NewRuntime(connectionOverride, outputFormat)
+			rt, err := runtime.NewRuntimeWithOptions(runtime.Options{
+				ContextOverride: connectionOverride,
+				OutputFormat:    outputFormat,
+				OutFile:         outFile,
+				NoColor:         noColor,
+				ConfigPath:      configPath,
+				SessionParams:   sessionParams,
+				Ephemeral:       ephemeral,
+
+				AllowConfigError: cmd.Annotations[runtime.AnnotationAllowConfigError] == "true",
+			})
 			if err != nil {
 				return err
 			}
@@ -44,14 +66,31 @@ func NewRootCmd() *cobra.Command {
 	rootCmd.Version = build.Version
 	rootCmd.SetVersionTemplate("{{.Version}}\n")
 	rootCmd.PersistentFlags().StringVarP(&connectionOverride, "connection", "c", "", "Snowflake connection to use (overrides the current connection)")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "json", "Output format. Supported: json")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", format.Default, fmt.Sprintf("Output format. Supported: %s", strings.Join(format.Names(), ", ")))
+	_ = rootCmd.RegisterFlagCompletionFunc("output", completeOutputFormats)
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to the config file (default $SNOWCTL_CONFIG or ~/.snowctl/config)")
+	rootCmd.PersistentFlags().StringArrayVar(&sessionParams, "session-param", nil, "Session parameter KEY=VALUE for this invocation, overriding the connection's params; KEY= unsets one (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&ephemeral.Account, "account", "", "Connect to this account without a saved connection; the secret is read from $SNOWCTL_SECRET or $SNOWFLAKE_PASSWORD")
+	rootCmd.PersistentFlags().StringVar(&ephemeral.User, "user", "", "User for an ephemeral connection (default $SNOWFLAKE_USER)")
+	rootCmd.PersistentFlags().StringVar(&ephemeral.Role, "role", "", "Role for an ephemeral connection (default $SNOWFLAKE_ROLE)")
+	rootCmd.PersistentFlags().StringVar(&ephemeral.Warehouse, "warehouse", "", "Warehouse for an ephemeral connection (default $SNOWFLAKE_WAREHOUSE)")
+	rootCmd.PersistentFlags().StringVar(&ephemeral.AuthMethod, "auth-method", "", "Auth method for an ephemeral connection: password or pat")
+	rootCmd.PersistentFlags().BoolVar(&ephemeral.FromEnv, "ephemeral", false, "Build the connection from SNOWCTL_*/SNOWFLAKE_* variables instead of the config file (also $SNOWCTL_EPHEMERAL)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also honours NO_COLOR)")
+	rootCmd.PersistentFlags().StringVar(&outFile, "out-file", "", "Write structured output to a file instead of stdout (required for binary formats such as xlsx)")
 	rootCmd.AddCommand(
+		configcmd.NewConfigCmd(),
 		connectioncmd.NewConnectionCmd(),
+		doctorcmd.NewDoctorCmd(),
 		showcmd.NewShowCmd(),
 		sqlcmd.NewSQLCmd(),
 	)
 
 	rootCmd.AddCommand(newCompletionCmd(rootCmd))
+	rootCmd.SetFlagErrorFunc(func(c *cobra.Command, err error) error {
+		return clierror.Usage(err)
+	})
+	markUsageErrors(rootCmd)
 	defaultHelp := rootCmd.HelpFunc()
 	rootCmd.SetHelpFunc(func(c *cobra.Command, args []string) {
 		if c == rootCmd {
@@ -64,18 +103,53 @@ func NewRootCmd() *cobra.Command {
 	return rootCmd
 }
 
-// Execute runs the root snowctl command.
+// Execute runs the root snowctl command and exits with the code for the error category on failure.
 func Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	root := NewRootCmd()
-	if err := root.Execute(); err != nil {
-		payload := formatExecutionError(err, root.CommandPath())
-		data, marshalErr := json.MarshalIndent(payload, "", "  ")
-		if marshalErr != nil {
-			fmt.Fprintln(os.Stderr, err)
-		} else {
-			fmt.Fprintln(os.Stderr, string(data))
+	executed, err := root.ExecuteContextC(ctx)
+	stop()
+	if err != nil {
+		var rt *runtime.Runtime
+		if executed != nil {
+			rt, _ = runtime.FromContext(executed.Context())
 		}
-		os.Exit(1)
+		os.Exit(printExecutionError(root, err, rt))
+	}
+}
+
+// printExecutionError renders the error envelope to stderr in the selected
+// output format and returns the process exit code.
+func printExecutionError(root *cobra.Command, err error, rt *runtime.Runtime) int {
+	classified := clierror.Classify(err)
+	envelope := formatExecutionError(classified, root.CommandPath(), rt)
+
+	f, ok := format.Lookup(outputFormat)
+	if !ok || format.IsBinary(f) {
+		f, _ = format.Lookup(format.Default)
+	}
+	errOut := root.ErrOrStderr()
+	styler := style.For(errOut, noColor)
+	buf := &bytes.Buffer{}
+	if fmtErr := f.Format(buf, envelope); fmtErr != nil {
+		fmt.Fprintln(errOut, styler.Error(err.Error()))
+		return classified.ExitCode()
+	}
+	fmt.Fprint(errOut, styler.Error(strings.TrimRight(buf.String(), "\n"))+"\n")
+	return classified.ExitCode()
+}
+
+// markUsageErrors tags flag and positional-argument validation failures as
+// usage errors across the command tree.
+func markUsageErrors(cmd *cobra.Command) {
+	if cmd.Args != nil {
+		validate := cmd.Args
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			return clierror.Usage(validate(c, args))
+		}
+	}
+	for _, child := range cmd.Commands() {
+		markUsageErrors(child)
 	}
 }
 
@@ -101,33 +175,24 @@ func printRootHelp(cmd *cobra.Command) {
 
 	fmt.Fprintln(out, "Flags:")
 	flags := []string{
-		"-h, --help         Show help",
-		"-v, --version      Show version",
-		"-c, --connection   Use a connection",
-		"-o, --output       Output format",
+		"-h, --help          Show help",
+		"-v, --version       Show version",
+		"-c, --connection    Use a connection",
+		"-o, --output        Output format",
+		"    --out-file      Write output to a file",
+		"    --no-color      Disable colored output",
+		"    --config        Use an alternate config file",
+		"    --session-param Set a session parameter (KEY=VALUE)",
+		"    --account       Connect without a saved connection",
+		"    --ephemeral     Connect using SNOWFLAKE_* variables",
 	}
 	for _, f := range flags {
 		fmt.Fprintf(out, "  %s\n", f)
 	}
 }
 
-func formatExecutionError(err error, commandPath string) map[string]string {
-	payload := map[string]string{"error": err.Error()}
-	if hint := hintForError(err, commandPath); hint != "" {
-		payload["hint"] = hint
-	}
-	return payload
-}
-
-func hintForError(err error, commandPath string) string {
-	msg := err.Error()
-	if unknownCmd, ok := parseUnknownCommand(msg); ok {
-		if unknownCmd == "version" {
-			return fmt.Sprintf("Use '%s --version' to print the CLI version.", commandPath)
-		}
-		return fmt.Sprintf("Run '%s --help' to see available commands.", commandPath)
-	}
-	return ""
+func formatExecutionError(err *clierror.Error, commandPath string, rt *runtime.Runtime) clierror.Envelope {
+	return clierror.NewEnvelope(err, hintForError(err, commandPath, rt))
 }
 
 func parseUnknownCommand(msg string) (string, bool) {
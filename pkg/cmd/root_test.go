@@ -4,11 +4,17 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 	"testing"
 
+	"github.com/snowflakedb/gosnowflake"
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
 )
 
@@ -49,7 +55,7 @@ func TestPrintRootHelpIncludesSections(t *testing.T) {
 
 func TestHintForUnknownVersionCommand(t *testing.T) {
 	err := errors.New(`unknown command "version" for "snowctl"`)
-	hint := hintForError(err, "snowctl")
+	hint := hintForError(err, "snowctl", nil)
 	expected := "Use 'snowctl --version' to print the CLI version."
 	if hint != expected {
 		t.Fatalf("expected %q, got %q", expected, hint)
@@ -58,7 +64,7 @@ func TestHintForUnknownVersionCommand(t *testing.T) {
 
 func TestHintForUnknownCommandDefaultsToHelp(t *testing.T) {
 	err := errors.New(`unknown command "versoin" for "snowctl"`)
-	hint := hintForError(err, "./snowctl")
+	hint := hintForError(err, "./snowctl", nil)
 	expected := "Run './snowctl --help' to see available commands."
 	if hint != expected {
 		t.Fatalf("expected %q, got %q", expected, hint)
@@ -67,7 +73,127 @@ func TestHintForUnknownCommandDefaultsToHelp(t *testing.T) {
 
 func TestHintForErrorWithoutUnknownCommand(t *testing.T) {
 	err := errors.New("some other failure")
-	if hint := hintForError(err, "snowctl"); hint != "" {
+	if hint := hintForError(err, "snowctl", nil); hint != "" {
 		t.Fatalf("expected empty hint, got %q", hint)
 	}
 }
+
+func TestCompleteOutputFormatsUsesRegistry(t *testing.T) {
+	values, directive := completeOutputFormats(NewRootCmd(), nil, "")
+	if directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Fatalf("unexpected directive %v", directive)
+	}
+	joined := strings.Join(values, "\n")
+	for _, name := range format.Names() {
+		if !strings.Contains(joined, name+"\t") {
+			t.Fatalf("expected %s in completions, got %v", name, values)
+		}
+	}
+}
+
+func TestPrintExecutionErrorRespectsOutputFormat(t *testing.T) {
+	root := NewRootCmd()
+	orig := outputFormat
+	outputFormat = "yaml"
+	defer func() { outputFormat = orig }()
+	buf := &bytes.Buffer{}
+	root.SetErr(buf)
+
+	code := printExecutionError(root, clierror.NotFoundf("connection %q not found", "ghost"), nil)
+	if code != 7 {
+		t.Fatalf("expected exit code 7, got %d", code)
+	}
+	if !strings.Contains(buf.String(), "category: not_found") {
+		t.Fatalf("expected yaml envelope, got %s", buf.String())
+	}
+}
+
+func TestArgsErrorsAreUsageErrors(t *testing.T) {
+	prepareRootRuntime(t)
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"connection", "remove"})
+
+	err := root.Execute()
+	if got := clierror.Classify(err); got.Category != clierror.CategoryUsage {
+		t.Fatalf("expected usage error, got %+v", got)
+	}
+}
+
+func TestHintForSnowflakeErrorCodes(t *testing.T) {
+	rt := &runtime.Runtime{
+		ActiveContextName: "analytics",
+		ActiveContext:     &config.Context{Name: "analytics", Role: "ANALYST"},
+	}
+	cases := []struct {
+		number int
+		want   []string
+	}{
+		{390100, []string{`connection "analytics"`, "snowctl connection test analytics"}},
+		{2003, []string{`role "ANALYST"`, "snowctl connection set analytics --role ROLE"}},
+		{606, []string{"No active warehouse", "snowctl connection set analytics --warehouse WAREHOUSE"}},
+	}
+	for _, tc := range cases {
+		err := fmt.Errorf("query failed: %w", &gosnowflake.SnowflakeError{Number: tc.number, Message: "boom"})
+		hint := hintForError(err, "snowctl", rt)
+		for _, want := range tc.want {
+			if !strings.Contains(hint, want) {
+				t.Fatalf("hint for %d = %q, expected it to contain %q", tc.number, hint, want)
+			}
+		}
+	}
+}
+
+func TestHintFallsBackToCategory(t *testing.T) {
+	err := clierror.New(clierror.CategoryNetwork, errors.New("dial tcp: i/o timeout"))
+	hint := hintForError(err, "snowctl", &runtime.Runtime{ActiveContextName: "dev"})
+	if !strings.Contains(hint, "snowctl connection test dev") {
+		t.Fatalf("expected network hint naming the connection, got %q", hint)
+	}
+}
+
+func TestHintForEphemeralConnection(t *testing.T) {
+	err := &gosnowflake.SnowflakeError{Number: 390100, Message: "Incorrect username or password was specified."}
+	hint := hintForError(err, "snowctl", &runtime.Runtime{ActiveContextName: config.EphemeralContextName, Ephemeral: true})
+	if strings.Contains(hint, "connection set") || !strings.Contains(hint, "SNOWFLAKE_PASSWORD") {
+		t.Fatalf("expected hint pointing at flags and variables, got %q", hint)
+	}
+
+	rt := &runtime.Runtime{ActiveContextName: config.EphemeralContextName, Ephemeral: true, ActiveContext: &config.Context{Role: "ANALYST"}}
+	err = &gosnowflake.SnowflakeError{Number: 2003, Message: "Object 'ORDERS' does not exist or not authorized."}
+	if hint := hintForError(err, "snowctl", rt); hint == ephemeralHint || !strings.Contains(hint, `role "ANALYST"`) || !strings.Contains(hint, "SNOWFLAKE_ROLE") {
+		t.Fatalf("expected the SQL hint for a SQL error, got %q", hint)
+	}
+	err = &gosnowflake.SnowflakeError{Number: 606, Message: "No active warehouse selected in the current session."}
+	if hint := hintForError(err, "snowctl", rt); !strings.Contains(hint, "'--warehouse WAREHOUSE' or SNOWFLAKE_WAREHOUSE") {
+		t.Fatalf("expected the warehouse hint to name the global flag, got %q", hint)
+	}
+
+	// 'connection set ephemeral' can never succeed, so no hint may suggest it.
+	for number := range snowflakeHints {
+		err := &gosnowflake.SnowflakeError{Number: number, Message: "failed"}
+		if hint := hintForError(err, "snowctl", rt); strings.Contains(hint, "connection set") {
+			t.Fatalf("error %d: hint for an ephemeral connection suggests connection set: %q", number, hint)
+		}
+	}
+	for category := range categoryHints {
+		if hint := hintForError(clierror.New(category, errors.New("failed")), "snowctl", rt); strings.Contains(hint, "connection set") {
+			t.Fatalf("%s: hint for an ephemeral connection suggests connection set: %q", category, hint)
+		}
+	}
+}
+
+func TestConnectionSetFlagsShadowEphemeralFlags(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := NewRootCmd()
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"connection", "set", "dev", "--account", "myorg-dev", "--help"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("expected connection set to accept its own --account flag: %v", err)
+	}
+	if ephemeral.Account != "" {
+		t.Fatalf("connection set --account must not request an ephemeral connection")
+	}
+}
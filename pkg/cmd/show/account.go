@@ -385,42 +385,46 @@ func getFloat64(row map[string]any, key string) float64 {
 }
 
 func printHumanSummary(cmd *cobra.Command, summary *accountSummary) {
+	styler := output.Styler(cmd, cmd.OutOrStdout())
+	label := func(format, text string) string {
+		return styler.Dim(fmt.Sprintf(format, text))
+	}
 	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
-	fmt.Fprintln(w, "Account summary")
+	fmt.Fprintln(w, styler.Bold("Account summary"))
 	fmt.Fprintln(w)
-	fmt.Fprintf(w, "%-20s %s\n", "Account:", summary.Context.Account)
-	fmt.Fprintf(w, "%-20s %s\n", "Account URL:", summary.Context.AccountURL)
-	fmt.Fprintf(w, "%-20s %s\n", "User:", summary.Context.User)
-	fmt.Fprintf(w, "%-20s %s\n", "Role:", summary.Context.Role)
-	fmt.Fprintf(w, "%-20s Last %d days\n", "Window:", summary.WindowDays)
+	fmt.Fprintf(w, "%s %s\n", label("%-20s", "Account:"), summary.Context.Account)
+	fmt.Fprintf(w, "%s %s\n", label("%-20s", "Account URL:"), summary.Context.AccountURL)
+	fmt.Fprintf(w, "%s %s\n", label("%-20s", "User:"), summary.Context.User)
+	fmt.Fprintf(w, "%s %s\n", label("%-20s", "Role:"), summary.Context.Role)
+	fmt.Fprintf(w, "%s Last %d days\n", label("%-20s", "Window:"), summary.WindowDays)
 	fmt.Fprintln(w)
 
 	if summary.User != nil {
-		fmt.Fprintln(w, "User profile:")
-		fmt.Fprintf(w, "    %-22s %s\n", "Login name:", summary.User.LoginName)
-		fmt.Fprintf(w, "    %-22s %s\n", "Email:", summary.User.Email)
-		fmt.Fprintf(w, "    %-22s %s\n", "Created:", humanTime(summary.User.CreatedOn))
-		fmt.Fprintf(w, "    %-22s %s\n", "Last success login:", humanTime(summary.User.LastSuccessLogin))
-		fmt.Fprintf(w, "    %-22s %t\n", "Disabled:", summary.User.Disabled)
+		fmt.Fprintln(w, styler.Bold("User profile:"))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Login name:"), summary.User.LoginName)
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Email:"), summary.User.Email)
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Created:"), humanTime(summary.User.CreatedOn))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Last success login:"), humanTime(summary.User.LastSuccessLogin))
+		fmt.Fprintf(w, "    %s %t\n", label("%-22s", "Disabled:"), summary.User.Disabled)
 		fmt.Fprintln(w)
 	}
 
 	if summary.LoginActivity != nil {
-		fmt.Fprintln(w, "Login activity:")
-		fmt.Fprintf(w, "    %-22s %s\n", "Logins:", humanCount(int64(summary.LoginActivity.LoginsLastWindow)))
-		fmt.Fprintf(w, "    %-22s %s\n", "Last login:", humanTime(summary.LoginActivity.LastLogin))
+		fmt.Fprintln(w, styler.Bold("Login activity:"))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Logins:"), humanCount(int64(summary.LoginActivity.LoginsLastWindow)))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Last login:"), humanTime(summary.LoginActivity.LastLogin))
 		fmt.Fprintln(w)
 	}
 
 	if summary.QueryActivity != nil {
-		fmt.Fprintln(w, "Query activity:")
-		fmt.Fprintf(w, "    %-22s %s\n", "Queries:", humanCount(summary.QueryActivity.Queries))
-		fmt.Fprintf(w, "    %-22s %s\n", "Runtime:", humanDuration(summary.QueryActivity.TotalSeconds))
-		fmt.Fprintf(w, "    %-22s %s\n", "Bytes scanned:", humanBytes(summary.QueryActivity.BytesScanned))
+		fmt.Fprintln(w, styler.Bold("Query activity:"))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Queries:"), humanCount(summary.QueryActivity.Queries))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Runtime:"), humanDuration(summary.QueryActivity.TotalSeconds))
+		fmt.Fprintf(w, "    %s %s\n", label("%-22s", "Bytes scanned:"), humanBytes(summary.QueryActivity.BytesScanned))
 		fmt.Fprintln(w)
 	}
 
-	fmt.Fprintln(w, "Warehouse usage:")
+	fmt.Fprintln(w, styler.Bold("Warehouse usage:"))
 	if len(summary.WarehouseTop) == 0 {
 		fmt.Fprintln(w, "    (no data)")
 	} else {
@@ -0,0 +1,46 @@
+package sqlcmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/sqlstmt"
+)
+
+// confirmProtected guards mutating statements on a protected connection: it
+// names the connection on stderr and requires the name to be typed back, or
+// --yes-i-am-sure when there is no terminal to ask. Read-only connections are
+// left to the query path, which refuses mutating statements outright.
+func (o *sqlOptions) confirmProtected(cmd *cobra.Command, ctx *config.Context, stmts []sqlstmt.Statement) error {
+	mutating := sqlstmt.Mutating(stmts)
+	if !ctx.IsProtected() || ctx.IsReadOnly() || len(mutating) == 0 {
+		return nil
+	}
+
+	errOut := cmd.ErrOrStderr()
+	styler := output.Styler(cmd, errOut)
+	fmt.Fprintln(errOut, styler.Error(styler.Bold(fmt.Sprintf("PROTECTED CONNECTION %q (account %s)", ctx.Name, ctx.Account))))
+	fmt.Fprintf(errOut, "%d statement(s) would change data, objects, or grants:\n", len(mutating))
+	for _, s := range mutating {
+		fmt.Fprintf(errOut, "  %s  %s\n", styler.Warning(strings.ToUpper(string(s.Kind))), s.Summary())
+	}
+
+	if o.yesIAmSure {
+		return nil
+	}
+	if !interactiveFn(cmd.InOrStdin()) {
+		return clierror.Usage(fmt.Errorf("connection %q is protected; pass --yes-i-am-sure to run mutating statements non-interactively", ctx.Name))
+	}
+	fmt.Fprintf(errOut, "Type the connection name (%s) to continue: ", ctx.Name)
+	answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	if strings.TrimSpace(answer) != ctx.Name {
+		return clierror.Newf(clierror.CategoryCancelled, "confirmation did not match %q; nothing was run", ctx.Name)
+	}
+	return nil
+}
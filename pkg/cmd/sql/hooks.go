@@ -1,5 +1,18 @@
 package sqlcmd
 
-import "github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
+import (
+	"io"
+	"os"
 
-var runQueryFn = snowflake.RunQuery
+	"golang.org/x/term"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
+)
+
+var queryFn = snowflake.Query
+
+// interactiveFn reports whether confirmations can be read from r.
+var interactiveFn = func(r io.Reader) bool {
+	file, ok := r.(*os.File)
+	return ok && term.IsTerminal(int(file.Fd()))
+}
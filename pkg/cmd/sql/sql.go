@@ -3,11 +3,16 @@ package sqlcmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/output"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/sqlstmt"
 )
 
 func NewSQLCmd() *cobra.Command {
@@ -21,17 +26,19 @@ func NewSQLCmd() *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&opts.statement, "query", "q", "", "SQL query to execute")
 	cmd.Flags().StringVar(&opts.statement, "text", "", "SQL query to execute (alias of --query)")
+	cmd.Flags().BoolVar(&opts.yesIAmSure, "yes-i-am-sure", false, "Run mutating statements on a protected connection without typing its name")
 	return cmd
 }
 
 type sqlOptions struct {
-	statement string
+	statement  string
+	yesIAmSure bool
 }
 
 func (o *sqlOptions) run(cmd *cobra.Command) error {
 	stmt := strings.TrimSpace(o.statement)
 	if stmt == "" {
-		return fmt.Errorf("query is required. Use --query \"SELECT ...\"")
+		return clierror.Usage(fmt.Errorf("query is required. Use --query \"SELECT ...\""))
 	}
 
 	ctx, err := runtime.RequireActiveContext(cmd.Context())
@@ -40,10 +47,13 @@ func (o *sqlOptions) run(cmd *cobra.Command) error {
 	}
 
 	if strings.TrimSpace(ctx.Secret) == "" {
-		return fmt.Errorf("connection %q has no stored credential. Re-run 'snowctl connection set %s' to store one.", ctx.Name, ctx.Name)
+		return clierror.Configf("connection %q has no stored credential. Re-run 'snowctl connection set %s' to store one.", ctx.Name, ctx.Name)
+	}
+	if err := o.confirmProtected(cmd, ctx, sqlstmt.Split(stmt)); err != nil {
+		return err
 	}
 
-	rows, err := runQueryFn(cmd.Context(), ctx, stmt)
+	result, err := queryFn(cmd.Context(), ctx, stmt)
 	if err != nil {
 		return fmt.Errorf("query failed: %w", err)
 	}
@@ -51,26 +61,45 @@ func (o *sqlOptions) run(cmd *cobra.Command) error {
 	resp := queryResponse{
 		Statement:  stmt,
 		Connection: ctx.Name,
-		Rows:       rows,
+		QueryID:    result.QueryID,
+		RunTime:    result.Duration.Round(time.Millisecond).String(),
+		Columns:    result.Columns,
+		Rows:       result.Rows,
 	}
 	return output.Print(cmd, resp)
 }
 
 type queryResponse struct {
-	Connection string           `json:"connection" yaml:"connection"`
-	Statement  string           `json:"statement" yaml:"statement"`
-	Rows       []map[string]any `json:"rows" yaml:"rows"`
+	Connection string             `json:"connection" yaml:"connection"`
+	Statement  string             `json:"statement" yaml:"statement"`
+	QueryID    string             `json:"queryId,omitempty" yaml:"queryId,omitempty"`
+	RunTime    string             `json:"runTime,omitempty" yaml:"runTime,omitempty"`
+	Columns    []snowflake.Column `json:"-" yaml:"-"`
+	Rows       []map[string]any   `json:"rows" yaml:"rows"`
 }
 
 func (r queryResponse) OutputMetadata() (interface{}, interface{}) {
 	meta := responseMetadata{
 		Connection: r.Connection,
 		Statement:  r.Statement,
+		QueryID:    r.QueryID,
+		RunTime:    r.RunTime,
 	}
 	return meta, r.Rows
 }
 
+// OutputColumns exposes the result column order and types to typed formats such as xlsx.
+func (r queryResponse) OutputColumns() []format.Column {
+	cols := make([]format.Column, len(r.Columns))
+	for i, c := range r.Columns {
+		cols[i] = format.Column{Name: c.Name, Type: c.Type}
+	}
+	return cols
+}
+
 type responseMetadata struct {
 	Connection string `json:"connection" yaml:"connection"`
 	Statement  string `json:"statement" yaml:"statement"`
+	QueryID    string `json:"queryId,omitempty" yaml:"queryId,omitempty"`
+	RunTime    string `json:"runTime,omitempty" yaml:"runTime,omitempty"`
 }
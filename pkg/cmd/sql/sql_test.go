@@ -1,15 +1,20 @@
 package sqlcmd
 
 import (
+	"archive/zip"
 	"bytes"
 	"context"
 	"encoding/json"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/snowflake"
 )
 
 func prepareSQLRuntime(t *testing.T) *runtime.Runtime {
@@ -33,17 +38,17 @@ func prepareSQLRuntime(t *testing.T) *runtime.Runtime {
 func TestSQLCommandOutputsJSON(t *testing.T) {
 	rt := prepareSQLRuntime(t)
 
-	orig := runQueryFn
-	runQueryFn = func(ctx context.Context, info *config.Context, stmt string) ([]map[string]any, error) {
+	orig := queryFn
+	queryFn = func(ctx context.Context, info *config.Context, stmt string) (*snowflake.QueryResult, error) {
 		if stmt != "select 1" {
 			t.Fatalf("expected statement select 1, got %s", stmt)
 		}
 		if info.Secret != "secret" {
 			t.Fatalf("expected stored secret, got %s", info.Secret)
 		}
-		return []map[string]any{{"COL1": float64(1)}}, nil
+		return &snowflake.QueryResult{Rows: []map[string]any{{"COL1": float64(1)}}}, nil
 	}
-	defer func() { runQueryFn = orig }()
+	defer func() { queryFn = orig }()
 
 	cmd := NewSQLCmd()
 	buf := &bytes.Buffer{}
@@ -81,3 +86,171 @@ func TestSQLCommandOutputsJSON(t *testing.T) {
 		t.Fatalf("expected 1 row")
 	}
 }
+
+func TestSQLCommandWritesXLSXToFile(t *testing.T) {
+	prepareSQLRuntime(t)
+	outPath := filepath.Join(t.TempDir(), "results.xlsx")
+	rt, err := runtime.NewRuntimeWithOptions(runtime.Options{OutputFormat: "xlsx", OutFile: outPath})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+
+	orig := queryFn
+	queryFn = func(ctx context.Context, info *config.Context, stmt string) (*snowflake.QueryResult, error) {
+		return &snowflake.QueryResult{
+			QueryID: "01b2-abc",
+			Columns: []snowflake.Column{{Name: "N", Type: "FIXED"}, {Name: "NOTE", Type: "TEXT"}},
+			Rows:    []map[string]any{{"N": "42", "NOTE": `Tom & "Jerry" <3`}, {"N": "7", "NOTE": "N"}},
+		}, nil
+	}
+	defer func() { queryFn = orig }()
+
+	cmd := NewSQLCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetContext(runtime.WithRuntime(context.Background(), rt))
+	cmd.SetArgs([]string{"--query", "select 42 as n, 'Tom & \"Jerry\" <3' as note"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	zr, err := zip.OpenReader(outPath)
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	defer zr.Close()
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		parts[f.Name] = string(body)
+	}
+
+	// Strings are numbered in order of first use: N, NOTE, the escaped note.
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	for _, want := range []string{
+		`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>`,
+		`<row r="2"><c r="A2"><v>42</v></c><c r="B2" t="s"><v>2</v></c></row>`,
+		`<row r="3"><c r="A3"><v>7</v></c><c r="B3" t="s"><v>0</v></c></row>`,
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Fatalf("expected %s in results sheet, got %s", want, sheet)
+		}
+	}
+	sst := parts["xl/sharedStrings.xml"]
+	want := `<si><t xml:space="preserve">N</t></si>` +
+		`<si><t xml:space="preserve">NOTE</t></si>` +
+		`<si><t xml:space="preserve">Tom &amp; &#34;Jerry&#34; &lt;3</t></si>`
+	if !strings.Contains(sst, want) {
+		t.Fatalf("expected header and escaped cell text in shared strings, got %s", sst)
+	}
+}
+
+func TestSQLCommandGuardsProtectedConnection(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	cfg := config.DefaultConfig()
+	protected := true
+	cfg.SetContext("prod", &config.Context{Account: "acct", AuthMethod: "password", Secret: "secret", Protected: &protected})
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	rt, err := runtime.NewRuntime("", "json")
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+
+	var ran []string
+	origQuery, origInteractive := queryFn, interactiveFn
+	queryFn = func(ctx context.Context, info *config.Context, stmt string) (*snowflake.QueryResult, error) {
+		ran = append(ran, stmt)
+		return &snowflake.QueryResult{}, nil
+	}
+	defer func() { queryFn, interactiveFn = origQuery, origInteractive }()
+
+	run := func(interactive bool, input string, args ...string) (string, error) {
+		interactiveFn = func(io.Reader) bool { return interactive }
+		cmd := NewSQLCmd()
+		stderr := &bytes.Buffer{}
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(stderr)
+		cmd.SetIn(strings.NewReader(input))
+		cmd.SetContext(runtime.WithRuntime(context.Background(), rt))
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stderr.String(), err
+	}
+
+	if _, err := run(false, "", "-q", "select * from orders"); err != nil {
+		t.Fatalf("read-only statement should not need confirmation: %v", err)
+	}
+	if _, err := run(false, "", "-q", "delete from orders"); err == nil || !strings.Contains(err.Error(), "--yes-i-am-sure") {
+		t.Fatalf("expected non-interactive refusal, got %v", err)
+	}
+	stderr, err := run(true, "dev\n", "-q", "delete from orders")
+	if err == nil || !strings.Contains(stderr, `PROTECTED CONNECTION "prod"`) || !strings.Contains(stderr, "DML  delete from orders") {
+		t.Fatalf("expected mismatched name to cancel after showing the connection, got %v: %s", err, stderr)
+	}
+	if _, err := run(true, "prod\n", "-q", "delete from orders"); err != nil {
+		t.Fatalf("typed name should confirm: %v", err)
+	}
+	if _, err := run(false, "", "-q", "grant role r to user u", "--yes-i-am-sure"); err != nil {
+		t.Fatalf("--yes-i-am-sure should confirm: %v", err)
+	}
+	if strings.Join(ran, ";") != "select * from orders;delete from orders;grant role r to user u" {
+		t.Fatalf("unexpected statements sent: %v", ran)
+	}
+}
+
+func TestSQLCommandRefusesWritesOnReadOnlyConnection(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := config.DefaultConfig()
+	on := true
+	cfg.SetContext("analyst", &config.Context{Account: "acct", AuthMethod: "password", Secret: "secret", ReadOnly: &on, Protected: &on})
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save config: %v", err)
+	}
+	rt, err := runtime.NewRuntime("", "json")
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+
+	orig := queryFn
+	queryFn = func(ctx context.Context, info *config.Context, stmt string) (*snowflake.QueryResult, error) {
+		// Mirror snowflake.Query: the refusal comes from the shared query path.
+		if err := snowflake.CheckReadOnly(info, stmt); err != nil {
+			return nil, err
+		}
+		if stmt != "select 1" {
+			t.Fatalf("unexpected statement sent: %s", stmt)
+		}
+		return &snowflake.QueryResult{}, nil
+	}
+	defer func() { queryFn = orig }()
+
+	for stmt, wantErr := range map[string]bool{"select 1": false, "insert into t values (1)": true, "create table t (id int)": true} {
+		cmd := NewSQLCmd()
+		stderr := &bytes.Buffer{}
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(stderr)
+		cmd.SetContext(runtime.WithRuntime(context.Background(), rt))
+		cmd.SetArgs([]string{"-q", stmt, "--yes-i-am-sure"})
+		err := cmd.Execute()
+		if (err != nil) != wantErr {
+			t.Fatalf("%q: unexpected error %v", stmt, err)
+		}
+		if wantErr && (!strings.Contains(err.Error(), "read-only") || strings.Contains(stderr.String(), "PROTECTED")) {
+			t.Fatalf("%q: expected read-only refusal before any confirmation, got %v (%s)", stmt, err, stderr.String())
+		}
+		if wantErr && clierror.Classify(err).Category != clierror.CategoryPolicy {
+			t.Fatalf("%q: expected a policy error, got %v", stmt, clierror.Classify(err).Category)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 
@@ -14,7 +16,9 @@ import (
 
 // Context stores the Snowflake connection profile.
 type Context struct {
-	Name        string `toml:"-"`
+	Name string `toml:"-"`
+	// Extends names a context whose settings fill any field left unset here.
+	Extends     string `toml:"extends,omitempty"`
 	Account     string `toml:"account,omitempty"`
 	AccountURL  string `toml:"accountUrl,omitempty"`
 	User        string `toml:"user,omitempty"`
@@ -25,13 +29,55 @@ type Context struct {
 	Description string `toml:"description,omitempty"`
 	AuthMethod  string `toml:"authMethod,omitempty"`
 	Secret      string `toml:"secret,omitempty"`
+	// Network settings for PrivateLink endpoints, proxies, and slow logins.
+	Host     string `toml:"host,omitempty"`
+	Port     int    `toml:"port,omitempty"`
+	Protocol string `toml:"protocol,omitempty"`
+	// Proxy is an http(s) proxy URL, optionally with user:password.
+	Proxy   string `toml:"proxy,omitempty"`
+	NoProxy string `toml:"noProxy,omitempty"`
+	// LoginTimeout is the login retry timeout in seconds; 0 uses the driver default.
+	LoginTimeout int `toml:"loginTimeout,omitempty"`
+	// OCSPFailOpen, when set, chooses fail-open (true) or fail-closed (false)
+	// certificate revocation checks; unset uses the driver default.
+	OCSPFailOpen *bool `toml:"ocspFailOpen,omitempty"`
+	// Params are Snowflake session parameters (QUERY_TAG, TIMEZONE, ...) set on
+	// every session opened with this context.
+	Params map[string]string `toml:"params,omitempty"`
+	// Labels are free-form key=value tags (env=prod, team=finance) matched by
+	// label selectors.
+	Labels map[string]string `toml:"labels,omitempty"`
+	// Protected makes sql show the connection name and ask for it to be typed
+	// before running statements that change data, objects, or grants. It is a
+	// pointer so a derived connection can set false over a protected parent.
+	Protected *bool `toml:"protected,omitempty"`
+	// ReadOnly makes snowctl refuse any statement that is not a read before
+	// sending it to Snowflake. Like Protected, false overrides a parent.
+	ReadOnly *bool `toml:"readOnly,omitempty"`
+}
+
+// IsProtected reports whether statements that change things need confirmation.
+func (ctx *Context) IsProtected() bool {
+	return ctx.Protected != nil && *ctx.Protected
+}
+
+// IsReadOnly reports whether only read statements may be sent.
+func (ctx *Context) IsReadOnly() bool {
+	return ctx.ReadOnly != nil && *ctx.ReadOnly
 }
 
 // Config describes the snowctl configuration schema.
 type Config struct {
+	SchemaVersion  int                 `toml:"schemaVersion,omitempty"`
 	CurrentContext string              `toml:"currentContext,omitempty"`
 	DefaultContext string              `toml:"defaultContext,omitempty"`
 	Contexts       map[string]*Context `toml:"contexts,omitempty"`
+
+	layers *layerState
+	// loaded is the flattened state read from disk; Save merges against it.
+	loaded map[string]any
+	// ephemeral marks configurations that must never be written; see NewEphemeral.
+	ephemeral bool
 }
 
 // DefaultConfig returns an initialized configuration.
@@ -40,7 +86,41 @@ func DefaultConfig() *Config {
 }
 
 // Load reads configuration from disk or returns defaults when files are missing.
+// A .snowctl.toml found by walking up from the working directory is merged
+// over the user configuration. A broken or cyclic extends chain does not fail
+// the load; it is reported when that connection is resolved, so the commands
+// that repair it keep working.
 func Load() (*Config, error) {
+	cfg, err := loadUserConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return cfg, nil
+	}
+	projectPath, err := findProjectConfig(wd)
+	if err != nil || projectPath == "" {
+		return cfg, err
+	}
+
+	userPath, err := path()
+	if err != nil {
+		return nil, err
+	}
+	userLayer, err := layerFromConfig(LayerUser, userPath, cfg)
+	if err != nil {
+		return nil, err
+	}
+	projectLayer, err := readLayer(LayerProject, projectPath)
+	if err != nil {
+		return nil, err
+	}
+	return mergeLayers([]*Layer{userLayer, projectLayer})
+}
+
+func loadUserConfig() (*Config, error) {
 	cfg := DefaultConfig()
 
 	cfgPath, err := path()
@@ -54,16 +134,33 @@ func Load() (*Config, error) {
 			if err := migrateLegacyConfig(cfg); err != nil {
 				return nil, err
 			}
+			if cfg.loaded == nil {
+				cfg.loaded = map[string]any{}
+			}
 			return cfg, nil
 		}
 		return nil, fmt.Errorf("read config: %w", err)
 	}
 
+	data, migrated, err := migrateConfigData(cfgPath, data, CurrentSchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	if migrated {
+		err := withFileLock(cfgPath, func() error { return writeFileAtomic(cfgPath, data) })
+		if err != nil {
+			return nil, fmt.Errorf("write migrated config: %w", err)
+		}
+	}
+
 	if err := toml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("parse config: %w", err)
 	}
 
 	cfg.ensureNames()
+	if cfg.loaded, err = flattenConfig(cfg); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
@@ -71,11 +168,11 @@ func migrateLegacyConfig(cfg *Config) error {
 	if cfg == nil {
 		return fmt.Errorf("nil config for migration")
 	}
-	dir, err := configDir()
+	cfgPath, err := path()
 	if err != nil {
 		return err
 	}
-	legacyPath := filepath.Join(dir, "config.json")
+	legacyPath := filepath.Join(filepath.Dir(cfgPath), "config.json")
 	data, err := os.ReadFile(legacyPath)
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -93,30 +190,115 @@ func migrateLegacyConfig(cfg *Config) error {
 	return nil
 }
 
-// Save writes the configuration atomically to disk.
+// Save writes the configuration atomically to disk. When a project layer was
+// merged at load time, each modified key is written only to the file that owns it.
+//
+// Saves hold an advisory lock and re-read the file first: only keys changed
+// since Load are written, so edits made concurrently by another snowctl
+// process are kept. If both processes changed the same key, Save returns an
+// error wrapping ErrConflict and leaves the file untouched.
 func Save(cfg *Config) error {
 	if cfg == nil {
 		return fmt.Errorf("nil config")
 	}
+	if cfg.ephemeral {
+		return ErrEphemeral
+	}
 	cfg.ensureNames()
+	cfg.SchemaVersion = CurrentSchemaVersion
 
 	cfgPath, err := path()
 	if err != nil {
 		return err
 	}
+	// A single lock beside the user config serialises every snowctl write,
+	// including project files, without littering repositories with lock files.
+	return withFileLock(cfgPath, func() error {
+		if cfg.layers != nil && len(cfg.layers.layers) > 1 {
+			return saveLayers(cfg)
+		}
+		return saveUserConfig(cfgPath, cfg)
+	})
+}
 
-	dir := filepath.Dir(cfgPath)
-	if err := os.MkdirAll(dir, 0o700); err != nil {
-		return fmt.Errorf("create config dir: %w", err)
+func saveUserConfig(cfgPath string, cfg *Config) error {
+	mine, err := flattenConfig(cfg)
+	if err != nil {
+		return err
+	}
+	if cfg.loaded == nil {
+		// Not read from disk: the caller owns the whole file.
+		if err := writeConfigFile(cfgPath, cfg); err != nil {
+			return err
+		}
+		cfg.loaded = mine
+		return nil
+	}
+	merged, err := saveMerged(cfgPath, cfg.loaded, mine)
+	if err != nil {
+		return err
+	}
+	cfg.loaded = merged
+	if !reflect.DeepEqual(merged, mine) {
+		fresh, err := configFromFlat(merged)
+		if err != nil {
+			return err
+		}
+		cfg.replaceWith(fresh)
+	}
+	return nil
+}
+
+// replaceWith adopts the settings of fresh, updating existing contexts in place
+// so pointers held by callers stay valid.
+func (c *Config) replaceWith(fresh *Config) {
+	c.SchemaVersion = fresh.SchemaVersion
+	c.CurrentContext = fresh.CurrentContext
+	c.DefaultContext = fresh.DefaultContext
+	contexts := make(map[string]*Context, len(fresh.Contexts))
+	for name, ctx := range fresh.Contexts {
+		if old, ok := c.Contexts[name]; ok && old != nil {
+			*old = *ctx
+			ctx = old
+		}
+		contexts[name] = ctx
 	}
+	c.Contexts = contexts
+}
 
+func writeConfigFile(cfgPath string, cfg *Config) error {
 	data, err := toml.Marshal(cfg)
 	if err != nil {
 		return fmt.Errorf("marshal config: %w", err)
 	}
+	return writeFileAtomic(cfgPath, data)
+}
 
-	tmpFile := cfgPath + ".tmp"
-	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+func writeFileAtomic(cfgPath string, data []byte) error {
+	dir := filepath.Dir(cfgPath)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(cfgPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create config temp file: %w", err)
+	}
+	tmpFile := tmp.Name()
+	defer os.Remove(tmpFile)
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write config temp file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write config temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write config temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
 		return fmt.Errorf("write config temp file: %w", err)
 	}
 	if err := os.Rename(tmpFile, cfgPath); err != nil {
@@ -125,6 +307,25 @@ func Save(cfg *Config) error {
 	return nil
 }
 
+// Clone returns a copy of ctx that shares no maps with it.
+func (ctx *Context) Clone() *Context {
+	copied := *ctx
+	copied.Params = maps.Clone(ctx.Params)
+	copied.Labels = maps.Clone(ctx.Labels)
+	copied.OCSPFailOpen = cloneBool(ctx.OCSPFailOpen)
+	copied.Protected = cloneBool(ctx.Protected)
+	copied.ReadOnly = cloneBool(ctx.ReadOnly)
+	return &copied
+}
+
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	v := *b
+	return &v
+}
+
 // GetContext returns the named context if present.
 func (c *Config) GetContext(name string) (*Context, bool) {
 	if c == nil {
@@ -142,9 +343,9 @@ func (c *Config) SetContext(name string, ctx *Context) {
 	if c.Contexts == nil {
 		c.Contexts = make(map[string]*Context)
 	}
-	copied := *ctx
+	copied := ctx.Clone()
 	copied.Name = name
-	c.Contexts[name] = &copied
+	c.Contexts[name] = copied
 	if c.CurrentContext == "" {
 		c.CurrentContext = name
 	}
@@ -153,6 +354,59 @@ func (c *Config) SetContext(name string, ctx *Context) {
 	}
 }
 
+// RenameContext moves the context oldName to newName. The currentContext and
+// defaultContext pointers and the extends of derived contexts follow it; the
+// names of the contexts whose extends changed are returned.
+func (c *Config) RenameContext(oldName, newName string) ([]string, error) {
+	ctx, ok := c.GetContext(oldName)
+	if !ok || ctx == nil {
+		return nil, fmt.Errorf("connection %q %w", oldName, ErrNotFound)
+	}
+	if err := c.checkNewName(newName); err != nil {
+		return nil, err
+	}
+	children := c.Children(oldName)
+	for _, child := range children {
+		c.Contexts[child].Extends = newName
+	}
+	delete(c.Contexts, oldName)
+	ctx.Name = newName
+	c.Contexts[newName] = ctx
+	if c.CurrentContext == oldName {
+		c.CurrentContext = newName
+	}
+	if c.DefaultContext == oldName {
+		c.DefaultContext = newName
+	}
+	return children, nil
+}
+
+// CopyContext stores a copy of src's own settings, including its secret and
+// extends, under dst and returns the copy for further edits. Pointers are left
+// alone unless the configuration had none.
+func (c *Config) CopyContext(src, dst string) (*Context, error) {
+	ctx, ok := c.GetContext(src)
+	if !ok || ctx == nil {
+		return nil, fmt.Errorf("connection %q %w", src, ErrNotFound)
+	}
+	if err := c.checkNewName(dst); err != nil {
+		return nil, err
+	}
+	c.SetContext(dst, ctx)
+	copied, _ := c.GetContext(dst)
+	return copied, nil
+}
+
+func (c *Config) checkNewName(name string) error {
+	if err := ValidateConnectionName(name); err != nil {
+		return err
+	}
+	if _, exists := c.GetContext(name); exists {
+		return fmt.Errorf("connection %q already exists", name)
+	}
+	return nil
+}
+
 // DeleteContext removes a context from the configuration.
 func (c *Config) DeleteContext(name string) {
 	if c.Contexts == nil {
@@ -243,7 +497,38 @@ func configDir() (string, error) {
 	return filepath.Join(home, ".snowctl"), nil
 }
 
+// EnvConfigPath names the environment variable that points snowctl at an alternate config file.
+const EnvConfigPath = "SNOWCTL_CONFIG"
+
+var pathOverride string
+
+// SetPath routes Load, Save, legacy migration, and Path to an alternate file,
+// taking precedence over SNOWCTL_CONFIG. An empty path restores the default.
+func SetPath(p string) error {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		pathOverride = ""
+		return nil
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return fmt.Errorf("resolve config path: %w", err)
+	}
+	pathOverride = abs
+	return nil
+}
+
 func path() (string, error) {
+	if pathOverride != "" {
+		return pathOverride, nil
+	}
+	if env := strings.TrimSpace(os.Getenv(EnvConfigPath)); env != "" {
+		abs, err := filepath.Abs(env)
+		if err != nil {
+			return "", fmt.Errorf("resolve %s: %w", EnvConfigPath, err)
+		}
+		return abs, nil
+	}
 	dir, err := configDir()
 	if err != nil {
 		return "", err
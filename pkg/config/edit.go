@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// EditError is a problem found in a hand-edited context. Line is the 1-based
+// line it was found on, or 0 when only the setting is known.
+type EditError struct {
+	Line int
+	Key  string
+	Err  error
+}
+
+func (e *EditError) Error() string {
+	switch {
+	case e.Line > 0:
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	case e.Key != "":
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+	return e.Err.Error()
+}
+
+func (e *EditError) Unwrap() error { return e.Err }
+
+// EditErrors lists every problem found in a hand-edited context.
+type EditErrors []*EditError
+
+func (e EditErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// EncodeContext renders the settings stored on ctx, not the inherited ones,
+// as a TOML document for editing by hand. The secret and any proxy password
+// are redacted; RestoreRedacted puts them back.
+func EncodeContext(ctx *Context) ([]byte, error) {
+	redacted := ctx.Clone()
+	if redacted.Secret != "" {
+		redacted.Secret = RedactedValue
+	}
+	redacted.Proxy = RedactProxy(redacted.Proxy)
+	data, err := toml.Marshal(redacted)
+	if err != nil {
+		return nil, fmt.Errorf("marshal connection: %w", err)
+	}
+	return data, nil
+}
+
+// DecodeContext parses a document written by EncodeContext. Unknown settings
+// are reported as EditErrors alongside the decoded context; syntax and type
+// errors return no context.
+func DecodeContext(data []byte) (*Context, error) {
+	dec := toml.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	ctx := &Context{}
+	err := dec.Decode(ctx)
+
+	var strict *toml.StrictMissingError
+	var decodeErr *toml.DecodeError
+	switch {
+	case err == nil:
+		return ctx, nil
+	case errors.As(err, &strict):
+		var errs EditErrors
+		for _, e := range strict.Errors {
+			line, _ := e.Position()
+			key := strings.Join(e.Key(), ".")
+			errs = append(errs, &EditError{Line: line, Key: key, Err: fmt.Errorf("unknown setting %q", key)})
+		}
+		return ctx, errs
+	case errors.As(err, &decodeErr):
+		line, _ := decodeErr.Position()
+		return nil, EditErrors{{Line: line, Err: errors.New(strings.TrimPrefix(decodeErr.Error(), "toml: "))}}
+	default:
+		return nil, EditErrors{{Err: err}}
+	}
+}
+
+// RestoreRedacted replaces the redaction markers EncodeContext wrote into
+// edited with the secret and proxy password of orig, so untouched values are
+// kept. The proxy password is restored whenever its mask was left in place,
+// even if the proxy host, port, or user changed.
+func RestoreRedacted(edited, orig *Context) {
+	if edited.Secret == RedactedValue {
+		edited.Secret = orig.Secret
+	}
+	if orig.Proxy == "" || edited.Proxy == "" {
+		return
+	}
+	if edited.Proxy == RedactProxy(orig.Proxy) {
+		edited.Proxy = orig.Proxy
+		return
+	}
+	origURL, err := url.Parse(orig.Proxy)
+	if err != nil || origURL.User == nil {
+		return
+	}
+	password, ok := origURL.User.Password()
+	if !ok {
+		return
+	}
+	editedURL, err := url.Parse(edited.Proxy)
+	if err != nil || editedURL.User == nil {
+		return
+	}
+	redacted, err := url.Parse(RedactProxy(orig.Proxy))
+	if err != nil || redacted.User == nil {
+		return
+	}
+	mask, _ := redacted.User.Password()
+	if got, ok := editedURL.User.Password(); ok && got == mask {
+		editedURL.User = url.UserPassword(editedURL.User.Username(), password)
+		edited.Proxy = editedURL.String()
+	}
+}
+
+// ValidateContext checks ctx as the new definition of the context name and
+// returns every problem found as EditErrors, or nil.
+func (c *Config) ValidateContext(name string, ctx *Context) error {
+	var errs EditErrors
+	add := func(key string, err error) {
+		errs = append(errs, &EditError{Key: key, Err: err})
+	}
+
+	effective := ctx
+	if ctx.Extends != "" {
+		if err := c.validateExtends(name, ctx.Extends); err != nil {
+			add("extends", err)
+		} else if parent, _, err := c.ResolveContext(ctx.Extends); err == nil {
+			effective = ctx.Inherit(parent)
+		}
+	}
+	for _, key := range []string{"account", "user", "secret"} {
+		idx, _ := contextField(key)
+		if reflect.ValueOf(effective).Elem().Field(idx).String() == "" {
+			add(key, fmt.Errorf("%s is required", key))
+		}
+	}
+	if ctx.Account != "" {
+		if err := ValidateAccountIdentifier(ctx.Account); err != nil {
+			add("account", err)
+		}
+	}
+	if ctx.AuthMethod != "" {
+		if err := ValidateAuthMethod(ctx.AuthMethod); err != nil {
+			add("authMethod", err)
+		}
+	}
+	// Check the network settings one at a time so each error names its key.
+	for _, key := range []string{"host", "port", "protocol", "proxy", "loginTimeout"} {
+		idx, _ := contextField(key)
+		probe := &Context{}
+		reflect.ValueOf(probe).Elem().Field(idx).Set(reflect.ValueOf(ctx).Elem().Field(idx))
+		if err := probe.ValidateNetwork(); err != nil {
+			add(key, err)
+		}
+	}
+	for _, key := range sortedKeys(ctx.Params) {
+		if _, _, err := ParseParam(key + "=" + ctx.Params[key]); err != nil {
+			add("params."+key, err)
+		}
+	}
+	for _, key := range sortedKeys(ctx.Labels) {
+		if _, _, err := ParseLabel(key + "=" + ctx.Labels[key]); err != nil {
+			add("labels."+key, err)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ChangedKeys returns the sorted config keys whose values differ between a
+// and b. Entries of params and labels are reported as params.KEY.
+func ChangedKeys(a, b *Context) ([]string, error) {
+	fa, err := flattenConfig(&Config{Contexts: map[string]*Context{"x": a}})
+	if err != nil {
+		return nil, err
+	}
+	fb, err := flattenConfig(&Config{Contexts: map[string]*Context{"x": b}})
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for k, v := range fa {
+		if w, ok := fb[k]; !ok || !reflect.DeepEqual(v, w) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range fb {
+		if _, ok := fa[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	for i, k := range keys {
+		keys[i] = strings.Join(strings.Split(k, keySep)[2:], ".")
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// validateExtends reports unknown parents and cycles for name extending parent.
+func (c *Config) validateExtends(name, parent string) error {
+	if parent == name {
+		return fmt.Errorf("connection %q cannot extend itself", name)
+	}
+	ancestors, err := c.Ancestors(parent)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(ancestors, name) {
+		return fmt.Errorf("connection %q cannot extend %q: extends cycle", name, parent)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
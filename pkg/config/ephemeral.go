@@ -0,0 +1,31 @@
+package config
+
+import (
+	"errors"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+)
+
+// EphemeralContextName names the in-memory context built from global flags and
+// environment variables.
+const EphemeralContextName = "ephemeral"
+
+// ErrEphemeral is returned by Save for configurations that exist only in
+// memory. It is a configuration error so commands can return it unwrapped.
+var ErrEphemeral error = clierror.New(clierror.CategoryConfig, errors.New("the connection was built from flags and environment variables and is never saved; drop --account/--ephemeral to change the config file"))
+
+// NewEphemeral returns an in-memory configuration whose only, current context
+// is ctx. Save refuses to write it, so credentials injected by CI never reach
+// disk.
+func NewEphemeral(ctx *Context) *Config {
+	cfg := DefaultConfig()
+	cfg.SetContext(EphemeralContextName, ctx)
+	cfg.CurrentContext = EphemeralContextName
+	cfg.ephemeral = true
+	return cfg
+}
+
+// Ephemeral reports whether cfg was built by NewEphemeral.
+func (c *Config) Ephemeral() bool {
+	return c != nil && c.ephemeral
+}
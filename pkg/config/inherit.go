@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ResolveContext returns the effective settings of the named context. Fields
+// left unset are filled from the contexts named by extends, nearest ancestor
+// first. inherited maps the config key of every filled field to the ancestor
+// that supplied it. The returned Context is a copy; its Extends is the
+// declared parent.
+func (c *Config) ResolveContext(name string) (*Context, map[string]string, error) {
+	chain, err := c.extendsChain(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	own, _ := c.GetContext(name)
+	resolved := own.Clone()
+	inherited := map[string]string{}
+	for _, ancestor := range chain[1:] {
+		parent, _ := c.GetContext(ancestor)
+		inheritFields(resolved, parent, func(key string) { inherited[key] = ancestor })
+	}
+	return resolved, inherited, nil
+}
+
+// Ancestors returns the contexts name inherits from, nearest parent first.
+func (c *Config) Ancestors(name string) ([]string, error) {
+	chain, err := c.extendsChain(name)
+	if err != nil {
+		return nil, err
+	}
+	return chain[1:], nil
+}
+
+// Inherit returns a copy of ctx whose unset fields are taken from parent.
+func (ctx *Context) Inherit(parent *Context) *Context {
+	res := ctx.Clone()
+	if parent != nil {
+		inheritFields(res, parent, func(string) {})
+	}
+	return res
+}
+
+// Children returns the sorted names of contexts that extend name directly.
+func (c *Config) Children(name string) []string {
+	var children []string
+	for child, ctx := range c.Contexts {
+		if ctx != nil && ctx.Extends == name {
+			children = append(children, child)
+		}
+	}
+	sort.Strings(children)
+	return children
+}
+
+// StripInherited clears fields of ctx whose value equals parent's, so they keep
+// following the parent instead of being pinned to today's value.
+func StripInherited(ctx, parent *Context) {
+	if ctx == nil || parent == nil {
+		return
+	}
+	cv := reflect.ValueOf(ctx).Elem()
+	pv := reflect.ValueOf(parent).Elem()
+	forEachInheritable(func(i int, _ string) {
+		field, parentField := cv.Field(i), pv.Field(i)
+		if field.Kind() == reflect.Map && !field.IsNil() {
+			stripMapEntries(field, parentField)
+			return
+		}
+		if reflect.DeepEqual(field.Interface(), parentField.Interface()) {
+			field.Set(reflect.Zero(field.Type()))
+		}
+	})
+}
+
+// extendsChain returns name followed by its ancestors, reporting unknown
+// parents and cycles.
+func (c *Config) extendsChain(name string) ([]string, error) {
+	ctx, ok := c.GetContext(name)
+	if !ok || ctx == nil {
+		return nil, fmt.Errorf("connection %q not found", name)
+	}
+	chain := []string{name}
+	seen := map[string]bool{name: true}
+	for ctx.Extends != "" {
+		parent := ctx.Extends
+		if seen[parent] {
+			return nil, fmt.Errorf("connection %q: extends cycle %s -> %s", name, strings.Join(chain, " -> "), parent)
+		}
+		next, ok := c.GetContext(parent)
+		if !ok || next == nil {
+			return nil, fmt.Errorf("connection %q extends unknown connection %q", chain[len(chain)-1], parent)
+		}
+		seen[parent] = true
+		chain = append(chain, parent)
+		ctx = next
+	}
+	return chain, nil
+}
+
+// inheritFields copies each unset field of dst from src, calling mark with the
+// config key of every field it fills. Map fields such as params are merged
+// entry by entry, with dst's entries winning.
+func inheritFields(dst, src *Context, mark func(key string)) {
+	dv := reflect.ValueOf(dst).Elem()
+	sv := reflect.ValueOf(src).Elem()
+	forEachInheritable(func(i int, key string) {
+		field, parentField := dv.Field(i), sv.Field(i)
+		if parentField.IsZero() {
+			return
+		}
+		if field.Kind() == reflect.Map {
+			if mergeMapEntries(field, parentField) {
+				mark(key)
+			}
+			return
+		}
+		if field.IsZero() {
+			field.Set(parentField)
+			mark(key)
+		}
+	})
+}
+
+// mergeMapEntries replaces dst with a copy that also holds src's entries for
+// keys dst lacks, reporting whether any were added.
+func mergeMapEntries(dst, src reflect.Value) bool {
+	merged := reflect.MakeMap(dst.Type())
+	iter := dst.MapRange()
+	for iter.Next() {
+		merged.SetMapIndex(iter.Key(), iter.Value())
+	}
+	added := false
+	iter = src.MapRange()
+	for iter.Next() {
+		if !merged.MapIndex(iter.Key()).IsValid() {
+			merged.SetMapIndex(iter.Key(), iter.Value())
+			added = true
+		}
+	}
+	dst.Set(merged)
+	return added
+}
+
+// stripMapEntries drops entries of field that parent holds with the same value.
+func stripMapEntries(field, parent reflect.Value) {
+	kept := reflect.MakeMap(field.Type())
+	iter := field.MapRange()
+	for iter.Next() {
+		pv := parent.MapIndex(iter.Key())
+		if pv.IsValid() && reflect.DeepEqual(pv.Interface(), iter.Value().Interface()) {
+			continue
+		}
+		kept.SetMapIndex(iter.Key(), iter.Value())
+	}
+	if kept.Len() == 0 {
+		kept = reflect.Zero(field.Type())
+	}
+	field.Set(kept)
+}
+
+// forEachInheritable visits the persisted Context fields other than extends.
+func forEachInheritable(fn func(i int, key string)) {
+	t := reflect.TypeOf(Context{})
+	for i := 0; i < t.NumField(); i++ {
+		key, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ",")
+		if key == "" || key == "-" || key == "extends" {
+			continue
+		}
+		fn(i, key)
+	}
+}
@@ -0,0 +1,228 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Label keys and values follow the Kubernetes shape so selectors stay
+// unambiguous: alphanumerics with '-', '_' and '.' inside, at most 63 runes.
+var labelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.-]{0,61}[A-Za-z0-9])?$`)
+
+// ParseLabel splits a key=value label assignment.
+func ParseLabel(assignment string) (string, string, error) {
+	key, value, ok := strings.Cut(assignment, "=")
+	key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid label %q: expected key=value", assignment)
+	}
+	if err := validateLabel("key", key); err != nil {
+		return "", "", err
+	}
+	if value != "" {
+		if err := validateLabel("value", value); err != nil {
+			return "", "", err
+		}
+	}
+	return key, value, nil
+}
+
+// ApplyLabels returns labels updated with the key=value assignments. An empty
+// value removes the key. The input map is not modified.
+func ApplyLabels(labels map[string]string, assignments []string) (map[string]string, error) {
+	res := maps.Clone(labels)
+	if res == nil {
+		res = map[string]string{}
+	}
+	for _, assignment := range assignments {
+		key, value, err := ParseLabel(assignment)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			delete(res, key)
+			continue
+		}
+		res[key] = value
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+func validateLabel(kind, s string) error {
+	if !labelPattern.MatchString(s) {
+		return fmt.Errorf("invalid label %s %q: use up to 63 letters, digits, '-', '_' or '.', starting and ending with a letter or digit", kind, s)
+	}
+	return nil
+}
+
+type selectorOp string
+
+const (
+	opEquals    selectorOp = "="
+	opNotEquals selectorOp = "!="
+	opIn        selectorOp = "in"
+	opNotIn     selectorOp = "notin"
+	opExists    selectorOp = "exists"
+	opNotExists selectorOp = "!"
+)
+
+type requirement struct {
+	key    string
+	op     selectorOp
+	values []string
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.op {
+	case opEquals, opIn:
+		return ok && slices.Contains(r.values, value)
+	case opNotEquals, opNotIn:
+		return !ok || !slices.Contains(r.values, value)
+	case opExists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+// Selector filters contexts by their labels. The zero Selector matches every
+// context.
+type Selector struct {
+	requirements []requirement
+}
+
+// ParseSelector parses a comma-separated list of label requirements, all of
+// which must hold:
+//
+//	env=prod, env==prod   equality
+//	env!=prod             inequality (also matches contexts without env)
+//	env in (prod,stage)   set membership
+//	env notin (dev)       set exclusion (also matches contexts without env)
+//	team, !team           presence and absence of a key
+func ParseSelector(s string) (Selector, error) {
+	var sel Selector
+	for _, term := range splitSelector(s) {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		req, err := parseRequirement(term)
+		if err != nil {
+			return Selector{}, fmt.Errorf("invalid selector %q: %w", s, err)
+		}
+		sel.requirements = append(sel.requirements, req)
+	}
+	return sel, nil
+}
+
+// Empty reports whether the selector has no requirements.
+func (s Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether labels satisfy every requirement.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, req := range s.requirements {
+		if !req.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// SelectContexts returns the sorted names of contexts whose effective labels,
+// with extends resolved, match sel. A context whose extends chain is broken
+// has no effective labels: the empty selector still returns it, so callers can
+// report the problem, but any other selector skips it.
+func (c *Config) SelectContexts(sel Selector) []string {
+	var names []string
+	for _, name := range c.ContextNames() {
+		ctx, _, err := c.ResolveContext(name)
+		if err != nil {
+			if sel.Empty() {
+				names = append(names, name)
+			}
+			continue
+		}
+		if sel.Matches(ctx.Labels) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// splitSelector splits on commas outside parentheses.
+func splitSelector(s string) []string {
+	var terms []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(terms, s[start:])
+}
+
+func parseRequirement(term string) (requirement, error) {
+	if key, ok := strings.CutPrefix(term, "!"); ok {
+		key = strings.TrimSpace(key)
+		return requirement{key: key, op: opNotExists}, validateLabel("key", key)
+	}
+	for _, op := range []selectorOp{opNotEquals, "==", opEquals} {
+		if key, value, ok := strings.Cut(term, string(op)); ok {
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			if err := validateLabel("key", key); err != nil {
+				return requirement{}, err
+			}
+			if err := validateLabel("value", value); err != nil {
+				return requirement{}, err
+			}
+			if op == "==" {
+				op = opEquals
+			}
+			return requirement{key: key, op: op, values: []string{value}}, nil
+		}
+	}
+	if fields := strings.Fields(term); len(fields) >= 2 && (fields[1] == string(opIn) || fields[1] == string(opNotIn)) {
+		key := fields[0]
+		if err := validateLabel("key", key); err != nil {
+			return requirement{}, err
+		}
+		list := strings.TrimSpace(strings.Join(fields[2:], " "))
+		inner, open := strings.CutPrefix(list, "(")
+		inner, closed := strings.CutSuffix(inner, ")")
+		if !open || !closed {
+			return requirement{}, fmt.Errorf("%s %s expects a parenthesised list, e.g. %s %s (a,b)", key, fields[1], key, fields[1])
+		}
+		var values []string
+		for _, v := range strings.Split(inner, ",") {
+			v = strings.TrimSpace(v)
+			if err := validateLabel("value", v); err != nil {
+				return requirement{}, err
+			}
+			values = append(values, v)
+		}
+		return requirement{key: key, op: selectorOp(fields[1]), values: values}, nil
+	}
+	if err := validateLabel("key", term); err != nil {
+		return requirement{}, err
+	}
+	return requirement{key: term, op: opExists}, nil
+}
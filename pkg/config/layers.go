@@ -0,0 +1,363 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ProjectFileName is the project-local configuration file discovered by walking
+// up from the working directory.
+const ProjectFileName = ".snowctl.toml"
+
+const (
+	// LayerUser is the per-user configuration under ~/.snowctl.
+	LayerUser = "user"
+	// LayerProject is a .snowctl.toml discovered from the working directory.
+	LayerProject = "project"
+)
+
+// Layer is one configuration file contributing to the effective Config.
+// Later layers override earlier ones key by key.
+type Layer struct {
+	Kind string
+	Path string
+	data map[string]any
+	// loaded is data as last read from or written to Path.
+	loaded map[string]any
+}
+
+// layerState remembers how an effective Config was assembled so Save can route
+// each modified key back to the file that owns it.
+type layerState struct {
+	layers   []*Layer
+	baseline map[string]any
+	sources  map[string]*Layer
+}
+
+// Layers returns the files that were merged into the configuration, lowest precedence first.
+func (c *Config) Layers() []*Layer {
+	if c == nil || c.layers == nil {
+		return nil
+	}
+	return c.layers.layers
+}
+
+// Source returns the path of the layer that supplied the given key, e.g.
+// Source("contexts", "prod", "role"). For a table such as params it returns
+// every layer that supplied an entry, joined with ", ". It returns "" when the
+// key is unset or the configuration was not loaded from disk.
+func (c *Config) Source(path ...string) string {
+	if c == nil || c.layers == nil {
+		return ""
+	}
+	key := flatKey(path)
+	if layer, ok := c.layers.sources[key]; ok {
+		return layer.Path
+	}
+	supplied := map[*Layer]bool{}
+	for k, layer := range c.layers.sources {
+		if strings.HasPrefix(k, key+keySep) {
+			supplied[layer] = true
+		}
+	}
+	var paths []string
+	for _, layer := range c.layers.layers {
+		if supplied[layer] {
+			paths = append(paths, layer.Path)
+		}
+	}
+	return strings.Join(paths, ", ")
+}
+
+// findProjectConfig walks up from dir looking for ProjectFileName.
+func findProjectConfig(dir string) (string, error) {
+	if dir == "" {
+		return "", nil
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, ProjectFileName)
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("inspect %s: %w", candidate, err)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", nil
+		}
+		dir = parent
+	}
+}
+
+func readLayer(kind, path string) (*Layer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s config: %w", kind, err)
+	}
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("parse %s config %s: %w", kind, path, err)
+	}
+	return newLayer(kind, path, flattenTree(tree)), nil
+}
+
+func layerFromConfig(kind, path string, cfg *Config) (*Layer, error) {
+	flat, err := flattenConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newLayer(kind, path, flat), nil
+}
+
+func newLayer(kind, path string, data map[string]any) *Layer {
+	return &Layer{Kind: kind, Path: path, data: data, loaded: copyTree(data)}
+}
+
+// mergeLayers overlays layers in order and decodes the result into a Config.
+func mergeLayers(layers []*Layer) (*Config, error) {
+	merged := map[string]any{}
+	sources := map[string]*Layer{}
+	for _, layer := range layers {
+		for k, v := range layer.data {
+			merged[k] = v
+			sources[k] = layer
+		}
+	}
+	cfg, err := configFromFlat(merged)
+	if err != nil {
+		return nil, err
+	}
+	baseline, err := flattenConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	cfg.layers = &layerState{layers: layers, baseline: baseline, sources: sources}
+	return cfg, nil
+}
+
+// saveLayers writes only the keys that changed since load, each to the layer that owns it.
+func saveLayers(cfg *Config) error {
+	state := cfg.layers
+	current, err := flattenConfig(cfg)
+	if err != nil {
+		return err
+	}
+
+	dirty := map[*Layer]bool{}
+	keys := map[string]struct{}{}
+	for k := range current {
+		keys[k] = struct{}{}
+	}
+	for k := range state.baseline {
+		keys[k] = struct{}{}
+	}
+	for k := range keys {
+		newVal, present := current[k]
+		oldVal, existed := state.baseline[k]
+		if present && existed && reflect.DeepEqual(newVal, oldVal) {
+			continue
+		}
+		if !present {
+			for _, layer := range state.layers {
+				if _, ok := layer.data[k]; ok {
+					delete(layer.data, k)
+					dirty[layer] = true
+				}
+			}
+			continue
+		}
+		owner := state.owner(k, newVal)
+		owner.data[k] = newVal
+		dirty[owner] = true
+		if owner == state.layers[0] && isCredentialKey(k, newVal) {
+			// A credential left in a higher layer would shadow the new value.
+			for _, layer := range state.layers[1:] {
+				if _, ok := layer.data[k]; ok {
+					delete(layer.data, k)
+					dirty[layer] = true
+				}
+			}
+		}
+	}
+
+	for _, layer := range state.layers {
+		if !dirty[layer] {
+			continue
+		}
+		merged, err := saveMerged(layer.Path, layer.loaded, layer.data)
+		if err != nil {
+			return err
+		}
+		layer.data = merged
+		layer.loaded = copyTree(merged)
+	}
+
+	fresh, err := mergeLayers(state.layers)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(fresh.layers.baseline, current) {
+		cfg.replaceWith(fresh)
+	}
+	cfg.layers = fresh.layers
+	return nil
+}
+
+// owner picks the layer that should receive a write to key: the highest layer
+// that already defines it, else the highest layer defining the enclosing
+// context, else the user layer. Credentials always go to the user layer so
+// they never land in a project file that may be committed.
+func (s *layerState) owner(key string, value any) *Layer {
+	if isCredentialKey(key, value) {
+		return s.layers[0]
+	}
+	for i := len(s.layers) - 1; i >= 0; i-- {
+		if _, ok := s.layers[i].data[key]; ok {
+			return s.layers[i]
+		}
+	}
+	parts := strings.Split(key, keySep)
+	if len(parts) > 2 && parts[0] == "contexts" {
+		prefix := flatKey(parts[:2])
+		for i := len(s.layers) - 1; i >= 0; i-- {
+			if s.layers[i].hasPrefix(prefix) {
+				return s.layers[i]
+			}
+		}
+	}
+	return s.layers[0]
+}
+
+// isCredentialKey reports whether a write of value to key stores a secret:
+// a context's secret, or a proxy URL that embeds a password.
+func isCredentialKey(key string, value any) bool {
+	parts := strings.Split(key, keySep)
+	if len(parts) != 3 || parts[0] != "contexts" {
+		return false
+	}
+	switch parts[2] {
+	case "secret":
+		return true
+	case "proxy":
+		raw, _ := value.(string)
+		u, err := url.Parse(raw)
+		if err != nil || u.User == nil {
+			return false
+		}
+		_, hasPassword := u.User.Password()
+		return hasPassword
+	}
+	return false
+}
+
+func (l *Layer) hasPrefix(prefix string) bool {
+	for k := range l.data {
+		if k == prefix || strings.HasPrefix(k, prefix+keySep) {
+			return true
+		}
+	}
+	return false
+}
+
+// keySep joins path segments of flattened keys; context names may contain dots.
+const keySep = "\x00"
+
+func flatKey(path []string) string {
+	return strings.Join(path, keySep)
+}
+
+func flattenConfig(cfg *Config) (map[string]any, error) {
+	data, err := toml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return flattenTree(tree), nil
+}
+
+// flattenTree converts nested tables into path keys. Empty tables are kept as
+// leaves so that contexts without fields still exist.
+func flattenTree(tree map[string]any) map[string]any {
+	out := map[string]any{}
+	var walk func(node map[string]any, prefix []string)
+	walk = func(node map[string]any, prefix []string) {
+		for k, v := range node {
+			path := append(append([]string(nil), prefix...), k)
+			if m, ok := v.(map[string]any); ok && len(m) > 0 {
+				walk(m, path)
+				continue
+			}
+			out[flatKey(path)] = v
+		}
+	}
+	walk(tree, nil)
+	return out
+}
+
+func unflatten(flat map[string]any) map[string]any {
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tree := map[string]any{}
+	for _, k := range keys {
+		parts := strings.Split(k, keySep)
+		node := tree
+		for _, p := range parts[:len(parts)-1] {
+			child, ok := node[p].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				node[p] = child
+			}
+			node = child
+		}
+		leaf := parts[len(parts)-1]
+		if m, ok := flat[k].(map[string]any); ok {
+			if _, exists := node[leaf]; !exists {
+				node[leaf] = copyTree(m)
+			}
+			continue
+		}
+		node[leaf] = flat[k]
+	}
+	return tree
+}
+
+func copyTree(m map[string]any) map[string]any {
+	res := make(map[string]any, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
+
+func configFromFlat(flat map[string]any) (*Config, error) {
+	data, err := toml.Marshal(unflatten(flat))
+	if err != nil {
+		return nil, fmt.Errorf("marshal config: %w", err)
+	}
+	cfg := DefaultConfig()
+	if err := toml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	cfg.ensureNames()
+	return cfg, nil
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ErrConflict reports that a key saved by this process was changed by another
+// process after the configuration was loaded.
+var ErrConflict = errors.New("config was modified by another snowctl process")
+
+// saveMerged writes mine to path as a three-way merge: the current file is
+// re-read, keys this process changed relative to base are applied on top of
+// it, and keys changed by others are kept. A key changed on both sides to
+// different values is a conflict and nothing is written. Callers hold the
+// config lock.
+func saveMerged(path string, base, mine map[string]any) (map[string]any, error) {
+	theirs, err := readFlat(path)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := mergeFlat(base, mine, theirs)
+	if err != nil {
+		return nil, fmt.Errorf("save %s: %w", path, err)
+	}
+	cfg, err := configFromFlat(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeConfigFile(path, cfg); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+func mergeFlat(base, mine, theirs map[string]any) (map[string]any, error) {
+	merged := make(map[string]any, len(theirs))
+	for k, v := range theirs {
+		merged[k] = v
+	}
+
+	keys := map[string]struct{}{}
+	for k := range base {
+		keys[k] = struct{}{}
+	}
+	for k := range mine {
+		keys[k] = struct{}{}
+	}
+	var conflicts []string
+	for k := range keys {
+		baseVal, inBase := base[k]
+		mineVal, inMine := mine[k]
+		if inBase == inMine && reflect.DeepEqual(baseVal, mineVal) {
+			continue
+		}
+		theirVal, inTheirs := theirs[k]
+		theirsChanged := inBase != inTheirs || !reflect.DeepEqual(baseVal, theirVal)
+		sameAsMine := inMine == inTheirs && reflect.DeepEqual(mineVal, theirVal)
+		if theirsChanged && !sameAsMine {
+			conflicts = append(conflicts, strings.ReplaceAll(k, keySep, "."))
+			continue
+		}
+		if inMine {
+			merged[k] = mineVal
+		} else {
+			delete(merged, k)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return nil, fmt.Errorf("%w: %s changed since it was loaded; re-run the command", ErrConflict, strings.Join(conflicts, ", "))
+	}
+	return merged, nil
+}
+
+// readFlat returns the flattened contents of path, or an empty map when the
+// file does not exist yet.
+func readFlat(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]any{}, nil
+		}
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return flattenTree(tree), nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// CurrentSchemaVersion is the schemaVersion written by this build of snowctl.
+const CurrentSchemaVersion = 1
+
+// migration upgrades a raw config tree from version from to from+1. Steps work
+// on the decoded TOML rather than Config so they can rename or reshape keys
+// that no longer exist in the struct.
+type migration struct {
+	from        int
+	description string
+	apply       func(tree map[string]any) error
+}
+
+// migrations is the ordered upgrade chain. Append a step and bump
+// CurrentSchemaVersion when the on-disk format changes.
+var migrations = []migration{
+	{
+		from:        0,
+		description: "record schemaVersion",
+		apply:       func(map[string]any) error { return nil },
+	},
+}
+
+// now is replaced in tests to produce predictable backup names.
+var now = time.Now
+
+// migrateConfigData runs the pending migrations up to target over data,
+// writing a backup of the file before each step. It returns the upgraded
+// document and whether anything changed.
+func migrateConfigData(cfgPath string, data []byte, target int) ([]byte, bool, error) {
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, false, fmt.Errorf("parse config: %w", err)
+	}
+	version, err := schemaVersionOf(tree)
+	if err != nil {
+		return nil, false, err
+	}
+	if version > target {
+		return nil, false, fmt.Errorf("config %s uses schema version %d, newer than this snowctl supports (%d); upgrade snowctl", cfgPath, version, target)
+	}
+	if version == target {
+		return data, false, nil
+	}
+
+	for _, step := range migrations {
+		if step.from < version {
+			continue
+		}
+		if version == target {
+			break
+		}
+		if step.from != version {
+			return nil, false, fmt.Errorf("no migration from config schema version %d", version)
+		}
+		if _, err := writeBackup(cfgPath, data, version); err != nil {
+			return nil, false, err
+		}
+		if err := step.apply(tree); err != nil {
+			return nil, false, fmt.Errorf("migrate config to schema version %d (%s): %w", version+1, step.description, err)
+		}
+		version++
+		tree["schemaVersion"] = int64(version)
+		if data, err = toml.Marshal(tree); err != nil {
+			return nil, false, fmt.Errorf("marshal migrated config: %w", err)
+		}
+	}
+	if version != target {
+		return nil, false, fmt.Errorf("no migration from config schema version %d", version)
+	}
+	return data, true, nil
+}
+
+func schemaVersionOf(tree map[string]any) (int, error) {
+	raw, ok := tree["schemaVersion"]
+	if !ok {
+		return 0, nil
+	}
+	v, ok := raw.(int64)
+	if !ok || v < 0 {
+		return 0, fmt.Errorf("invalid schemaVersion %v", raw)
+	}
+	return int(v), nil
+}
+
+// writeBackup copies the pre-migration document next to cfgPath, e.g.
+// config.v0-20260102T150405Z.bak.
+func writeBackup(cfgPath string, data []byte, version int) (string, error) {
+	stamp := now().UTC().Format("20060102T150405Z")
+	name := fmt.Sprintf("%s.v%d-%s.bak", filepath.Base(cfgPath), version, stamp)
+	backup := filepath.Join(filepath.Dir(cfgPath), name)
+	if err := os.WriteFile(backup, data, 0o600); err != nil {
+		return "", fmt.Errorf("write config backup: %w", err)
+	}
+	return backup, nil
+}
+
+// Backups lists migration backups written next to the configuration file.
+func Backups() ([]string, error) {
+	cfgPath, err := path()
+	if err != nil {
+		return nil, err
+	}
+	return filepath.Glob(filepath.Join(filepath.Dir(cfgPath), filepath.Base(cfgPath)+".v*.bak"))
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+)
+
+var paramKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseParam splits a KEY=VALUE session parameter assignment. Keys are
+// upper-cased because Snowflake parameter names are case-insensitive.
+func ParseParam(assignment string) (string, string, error) {
+	key, value, ok := strings.Cut(assignment, "=")
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if !ok || key == "" {
+		return "", "", fmt.Errorf("invalid session parameter %q: expected KEY=VALUE", assignment)
+	}
+	if !paramKeyPattern.MatchString(key) {
+		return "", "", fmt.Errorf("invalid session parameter name %q", key)
+	}
+	return key, strings.TrimSpace(value), nil
+}
+
+// ApplyParams returns params updated with the KEY=VALUE assignments. An empty
+// value removes the key. The input map is not modified.
+func ApplyParams(params map[string]string, assignments []string) (map[string]string, error) {
+	res := maps.Clone(params)
+	if res == nil {
+		res = map[string]string{}
+	}
+	for _, assignment := range assignments {
+		key, value, err := ParseParam(assignment)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			delete(res, key)
+			continue
+		}
+		res[key] = value
+	}
+	if len(res) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
@@ -0,0 +1,406 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotFound reports a dotted path naming a connection that does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrNotSet reports a dotted path whose setting has no value.
+	ErrNotSet = errors.New("not set")
+)
+
+// RedactedValue replaces secrets in redacted views.
+const RedactedValue = "REDACTED"
+
+// AuthMethods lists the supported values of Context.AuthMethod.
+var AuthMethods = []string{"password", "pat"}
+
+// ValidateAuthMethod reports whether method is one of AuthMethods.
+func ValidateAuthMethod(method string) error {
+	if !slices.Contains(AuthMethods, method) {
+		return fmt.Errorf("invalid auth method %q: must be %s", method, strings.Join(AuthMethods, " or "))
+	}
+	return nil
+}
+
+var topLevelKeys = []string{"schemaVersion", "currentContext", "defaultContext", "contexts"}
+
+// keyPath is a parsed dotted path such as contexts.prod.params.QUERY_TAG.
+type keyPath struct {
+	top     string
+	context string
+	field   string
+	// entry is the key within a map setting (params or labels).
+	entry string
+}
+
+func (p keyPath) String() string {
+	parts := []string{p.top}
+	for _, s := range []string{p.context, p.field, p.entry} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// parseKeyPath splits a dotted path. Connection names may contain dots, so the
+// setting is recognised from the end of the path.
+func (c *Config) parseKeyPath(path string) (keyPath, error) {
+	path = strings.TrimSpace(path)
+	if slices.Contains(topLevelKeys, path) {
+		return keyPath{top: path}, nil
+	}
+	rest, ok := strings.CutPrefix(path, "contexts.")
+	if !ok || rest == "" {
+		return keyPath{}, fmt.Errorf("unknown config key %q (expected one of %s, or contexts.NAME.SETTING)", path, strings.Join(topLevelKeys[1:], ", "))
+	}
+	if _, exists := c.Contexts[rest]; exists {
+		return keyPath{top: "contexts", context: rest}, nil
+	}
+	if i := strings.LastIndex(rest, ".params."); i > 0 {
+		key := strings.ToUpper(rest[i+len(".params."):])
+		if !paramKeyPattern.MatchString(key) {
+			return keyPath{}, fmt.Errorf("invalid session parameter name %q", key)
+		}
+		return keyPath{top: "contexts", context: rest[:i], field: "params", entry: key}, nil
+	}
+	if i := strings.LastIndex(rest, ".labels."); i > 0 {
+		key := rest[i+len(".labels."):]
+		if err := validateLabel("key", key); err != nil {
+			return keyPath{}, err
+		}
+		return keyPath{top: "contexts", context: rest[:i], field: "labels", entry: key}, nil
+	}
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return keyPath{top: "contexts", context: rest}, nil
+	}
+	field := rest[i+1:]
+	if _, ok := contextField(field); !ok {
+		return keyPath{}, fmt.Errorf("unknown connection setting %q (known: %s)", field, strings.Join(ContextKeys(), ", "))
+	}
+	return keyPath{top: "contexts", context: rest[:i], field: field}, nil
+}
+
+// ContextKeys lists the settings addressable as contexts.NAME.KEY.
+func ContextKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(Context{})
+	for i := 0; i < t.NumField(); i++ {
+		if key := tomlKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func contextField(key string) (int, bool) {
+	t := reflect.TypeOf(Context{})
+	for i := 0; i < t.NumField(); i++ {
+		if tomlKey(t.Field(i)) == key {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func tomlKey(f reflect.StructField) string {
+	key, _, _ := strings.Cut(f.Tag.Get("toml"), ",")
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// Get returns the stored value at a dotted path such as contexts.prod.role.
+// Inherited values are not resolved; secrets are redacted unless raw is set.
+func (c *Config) Get(path string, raw bool) (any, error) {
+	p, err := c.parseKeyPath(path)
+	if err != nil {
+		return nil, err
+	}
+	switch p.top {
+	case "schemaVersion":
+		return c.SchemaVersion, nil
+	case "currentContext":
+		return notEmpty(p, c.CurrentContext)
+	case "defaultContext":
+		return notEmpty(p, c.DefaultContext)
+	case "contexts":
+		if p.context == "" {
+			return c.ContextNames(), nil
+		}
+	}
+
+	ctx, ok := c.GetContext(p.context)
+	if !ok || ctx == nil {
+		return nil, fmt.Errorf("connection %q %w", p.context, ErrNotFound)
+	}
+	if p.field == "" {
+		tree, err := contextTree(ctx, raw)
+		if err != nil {
+			return nil, err
+		}
+		return tree, nil
+	}
+	if p.entry != "" {
+		value, ok := (*mapField(ctx, p.field))[p.entry]
+		if !ok {
+			return nil, fmt.Errorf("%s is %w", p, ErrNotSet)
+		}
+		return value, nil
+	}
+	idx, _ := contextField(p.field)
+	v := reflect.ValueOf(ctx).Elem().Field(idx)
+	if v.IsZero() {
+		return nil, fmt.Errorf("%s is %w", p, ErrNotSet)
+	}
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	return redactValue(p.field, v.Interface(), raw), nil
+}
+
+// Set parses value for the setting at path, validates it, and stores it.
+// Setting a field of an unknown connection creates that connection.
+func (c *Config) Set(path, value string) error {
+	p, err := c.parseKeyPath(path)
+	if err != nil {
+		return err
+	}
+	value = strings.TrimSpace(value)
+	switch p.top {
+	case "schemaVersion":
+		return fmt.Errorf("schemaVersion is managed by snowctl")
+	case "currentContext", "defaultContext":
+		if _, ok := c.GetContext(value); !ok {
+			return fmt.Errorf("connection %q %w", value, ErrNotFound)
+		}
+		if p.top == "currentContext" {
+			c.CurrentContext = value
+		} else {
+			c.DefaultContext = value
+		}
+		return nil
+	}
+	if p.field == "" {
+		return fmt.Errorf("cannot set %s directly; set its fields, e.g. %s.account", p, p)
+	}
+
+	ctx := &Context{}
+	existing, exists := c.GetContext(p.context)
+	if exists && existing != nil {
+		ctx = existing.Clone()
+	} else if err := ValidateConnectionName(p.context); err != nil {
+		return err
+	}
+
+	if p.entry != "" {
+		if err := applyEntry(ctx, p, value); err != nil {
+			return err
+		}
+	} else if err := setField(ctx, p.field, value); err != nil {
+		return fmt.Errorf("%s: %w", p, err)
+	}
+	if err := c.validateContextChange(p.context, ctx); err != nil {
+		return err
+	}
+
+	if exists && existing != nil {
+		*existing = *ctx
+	} else {
+		c.SetContext(p.context, ctx)
+	}
+	return nil
+}
+
+// Unset clears the setting at path. Unsetting contexts.NAME removes the
+// connection unless other connections extend it.
+func (c *Config) Unset(path string) error {
+	p, err := c.parseKeyPath(path)
+	if err != nil {
+		return err
+	}
+	switch p.top {
+	case "schemaVersion":
+		return fmt.Errorf("schemaVersion is managed by snowctl")
+	case "currentContext":
+		c.CurrentContext = ""
+		return nil
+	case "defaultContext":
+		c.DefaultContext = ""
+		return nil
+	case "contexts":
+		if p.context == "" {
+			return fmt.Errorf("refusing to unset every connection; remove them one at a time")
+		}
+	}
+
+	ctx, ok := c.GetContext(p.context)
+	if !ok || ctx == nil {
+		return fmt.Errorf("connection %q %w", p.context, ErrNotFound)
+	}
+	if p.field == "" {
+		if children := c.Children(p.context); len(children) > 0 {
+			return fmt.Errorf("connection %q is extended by %s", p.context, strings.Join(children, ", "))
+		}
+		c.DeleteContext(p.context)
+		return nil
+	}
+	if p.entry != "" {
+		if _, ok := (*mapField(ctx, p.field))[p.entry]; !ok {
+			return fmt.Errorf("%s is %w", p, ErrNotSet)
+		}
+		return applyEntry(ctx, p, "")
+	}
+	idx, _ := contextField(p.field)
+	v := reflect.ValueOf(ctx).Elem().Field(idx)
+	if v.IsZero() {
+		return fmt.Errorf("%s is %w", p, ErrNotSet)
+	}
+	v.Set(reflect.Zero(v.Type()))
+	return nil
+}
+
+// Redacted returns the configuration as a TOML-shaped tree with secrets and
+// proxy passwords replaced, suitable for display.
+func (c *Config) Redacted() (map[string]any, error) {
+	flat, err := flattenConfig(c)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range flat {
+		parts := strings.Split(k, keySep)
+		if len(parts) == 3 && parts[0] == "contexts" {
+			flat[k] = redactValue(parts[2], v, false)
+		}
+	}
+	return unflatten(flat), nil
+}
+
+func (c *Config) validateContextChange(name string, ctx *Context) error {
+	if ctx.AuthMethod != "" {
+		if err := ValidateAuthMethod(ctx.AuthMethod); err != nil {
+			return err
+		}
+	}
+	if ctx.Account != "" {
+		if err := ValidateAccountIdentifier(ctx.Account); err != nil {
+			return err
+		}
+	}
+	if err := ctx.ValidateNetwork(); err != nil {
+		return err
+	}
+	if ctx.Extends != "" {
+		return c.validateExtends(name, ctx.Extends)
+	}
+	return nil
+}
+
+// mapField returns the map setting named field.
+func mapField(ctx *Context, field string) *map[string]string {
+	if field == "labels" {
+		return &ctx.Labels
+	}
+	return &ctx.Params
+}
+
+// applyEntry sets one entry of a map setting; an empty value removes it.
+func applyEntry(ctx *Context, p keyPath, value string) error {
+	apply := ApplyParams
+	if p.field == "labels" {
+		apply = ApplyLabels
+	}
+	m := mapField(ctx, p.field)
+	updated, err := apply(*m, []string{p.entry + "=" + value})
+	if err != nil {
+		return err
+	}
+	*m = updated
+	return nil
+}
+
+// setField converts value to the type of the Context field named key.
+func setField(ctx *Context, key, value string) error {
+	idx, _ := contextField(key)
+	v := reflect.ValueOf(ctx).Elem().Field(idx)
+	switch v.Kind() {
+	case reflect.String:
+		if key == "authMethod" {
+			value = strings.ToLower(value)
+		}
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Pointer:
+		if v.Type().Elem().Kind() != reflect.Bool {
+			return fmt.Errorf("setting cannot be assigned from the command line")
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", value)
+		}
+		v.Set(reflect.ValueOf(&b))
+	default:
+		return fmt.Errorf("set individual entries instead, e.g. %s.KEY", key)
+	}
+	return nil
+}
+
+func contextTree(ctx *Context, raw bool) (map[string]any, error) {
+	flat, err := flattenConfig(&Config{Contexts: map[string]*Context{"x": ctx}})
+	if err != nil {
+		return nil, err
+	}
+	tree := unflatten(flat)
+	contexts, _ := tree["contexts"].(map[string]any)
+	res, _ := contexts["x"].(map[string]any)
+	if res == nil {
+		res = map[string]any{}
+	}
+	for k, v := range res {
+		res[k] = redactValue(k, v, raw)
+	}
+	return res, nil
+}
+
+func redactValue(key string, v any, raw bool) any {
+	if raw {
+		return v
+	}
+	switch key {
+	case "secret":
+		return RedactedValue
+	case "proxy":
+		if s, ok := v.(string); ok {
+			return RedactProxy(s)
+		}
+	}
+	return v
+}
+
+func notEmpty(p keyPath, v string) (any, error) {
+	if v == "" {
+		return nil, fmt.Errorf("%s is %w", p, ErrNotSet)
+	}
+	return v, nil
+}
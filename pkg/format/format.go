@@ -0,0 +1,122 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Default is the output format used when --output is not supplied.
+const Default = "json"
+
+// Formatter renders command payloads in a named output format.
+type Formatter interface {
+	// Name is the value accepted by the --output flag.
+	Name() string
+	// Description is a one-line summary shown in help and shell completion.
+	Description() string
+	// Streaming reports whether the format writes records incrementally rather
+	// than rendering the payload as a single document.
+	Streaming() bool
+	// Format writes data to w.
+	Format(w io.Writer, data interface{}) error
+}
+
+// BinaryFormatter is implemented by formats that emit binary documents. Such
+// formats cannot be written to a terminal and require --out-file.
+type BinaryFormatter interface {
+	Binary() bool
+}
+
+// IsBinary reports whether f produces binary output.
+func IsBinary(f Formatter) bool {
+	b, ok := f.(BinaryFormatter)
+	return ok && b.Binary()
+}
+
+// Column describes a result column for formats that emit typed cells.
+type Column struct {
+	Name string
+	// Type is the Snowflake type name, e.g. FIXED, REAL, TEXT, DATE, TIMESTAMP_NTZ.
+	Type string
+}
+
+// ColumnProvider allows callers to supply column order and types alongside rows.
+type ColumnProvider interface {
+	OutputColumns() []Column
+}
+
+// KeyHighlighter is implemented by document formats whose object keys can be
+// colorized after rendering for terminal output.
+type KeyHighlighter interface {
+	HighlightKeys(doc []byte, paint func(string) string) []byte
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Formatter{}
+	order      []string
+)
+
+// Register adds a formatter to the registry. It panics when the name is empty or already registered.
+func Register(f Formatter) {
+	name := strings.ToLower(strings.TrimSpace(f.Name()))
+	if name == "" {
+		panic("format: formatter name cannot be empty")
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, exists := registry[name]; exists {
+		panic(fmt.Sprintf("format: formatter %q already registered", name))
+	}
+	registry[name] = f
+	order = append(order, name)
+}
+
+// Lookup returns the formatter registered under name.
+func Lookup(name string) (Formatter, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	f, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	return f, ok
+}
+
+// Names returns registered format names in registration order.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return append([]string(nil), order...)
+}
+
+// All returns registered formatters in registration order.
+func All() []Formatter {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	res := make([]Formatter, 0, len(order))
+	for _, name := range order {
+		res = append(res, registry[name])
+	}
+	return res
+}
+
+// Normalize lower-cases name, applies the default, and validates it against the registry.
+func Normalize(name string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+	if normalized == "" {
+		normalized = Default
+	}
+	if _, ok := Lookup(normalized); !ok {
+		return "", fmt.Errorf("unsupported output format %q (supported: %s)", name, strings.Join(Names(), ", "))
+	}
+	return normalized, nil
+}
+
+func init() {
+	Register(jsonFormatter{})
+	Register(yamlFormatter{})
+	Register(separatedFormatter{name: "csv", description: "Comma-separated values", sep: ','})
+	Register(separatedFormatter{name: "tsv", description: "Tab-separated values", sep: '\t'})
+	Register(xlsxFormatter{})
+	Register(tableFormatter{})
+}
@@ -0,0 +1,145 @@
+package format
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+type stubFormatter struct{ name string }
+
+func (s stubFormatter) Name() string        { return s.name }
+func (s stubFormatter) Description() string { return "stub" }
+func (s stubFormatter) Streaming() bool     { return false }
+func (s stubFormatter) Format(w io.Writer, data interface{}) error {
+	_, err := io.WriteString(w, "stub")
+	return err
+}
+
+func TestBuiltinFormatsRegistered(t *testing.T) {
+	names := strings.Join(Names(), ",")
+	for _, want := range []string{"json", "yaml", "csv", "tsv"} {
+		if !strings.Contains(names, want) {
+			t.Fatalf("expected %s in registry, got %s", want, names)
+		}
+	}
+	if f, ok := Lookup("CSV"); !ok || f.Name() != "csv" {
+		t.Fatalf("expected csv lookup to be case-insensitive")
+	}
+}
+
+func TestBuiltinFormatsDeclareStreaming(t *testing.T) {
+	want := map[string]bool{"json": false, "yaml": false, "csv": true, "tsv": true, "xlsx": false, "table": true}
+	for name, streaming := range want {
+		f, ok := Lookup(name)
+		if !ok {
+			t.Fatalf("expected %s registered", name)
+		}
+		if f.Streaming() != streaming {
+			t.Fatalf("%s: Streaming() = %v, want %v", name, f.Streaming(), streaming)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	got, err := Normalize("")
+	if err != nil || got != Default {
+		t.Fatalf("expected default format, got %q (%v)", got, err)
+	}
+	got, err = Normalize(" YAML ")
+	if err != nil || got != "yaml" {
+		t.Fatalf("expected yaml, got %q (%v)", got, err)
+	}
+	if _, err := Normalize("xml"); err == nil || !strings.Contains(err.Error(), "supported: json") {
+		t.Fatalf("expected unsupported format error listing formats, got %v", err)
+	}
+}
+
+// restoreRegistry puts the registry back as it was when the test ends.
+func restoreRegistry(t *testing.T) {
+	t.Helper()
+	registryMu.RLock()
+	saved := make(map[string]Formatter, len(registry))
+	for name, f := range registry {
+		saved[name] = f
+	}
+	savedOrder := append([]string(nil), order...)
+	registryMu.RUnlock()
+	t.Cleanup(func() {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		registry, order = saved, savedOrder
+	})
+}
+
+func TestRegisterCustomFormatter(t *testing.T) {
+	restoreRegistry(t)
+	Register(stubFormatter{name: "stub-test"})
+	f, ok := Lookup("stub-test")
+	if !ok {
+		t.Fatalf("expected stub formatter registered")
+	}
+	buf := &bytes.Buffer{}
+	if err := f.Format(buf, nil); err != nil || buf.String() != "stub" {
+		t.Fatalf("unexpected stub output %q (%v)", buf.String(), err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected duplicate registration to panic")
+		}
+	}()
+	Register(stubFormatter{name: "stub-test"})
+}
+
+func TestHighlightKeys(t *testing.T) {
+	paint := func(s string) string { return "<" + s + ">" }
+
+	jsonDoc := []byte("{\n  \"name\": \"a:b\",\n  \"rows\": [\"x\"]\n}\n")
+	got := string(jsonFormatter{}.HighlightKeys(jsonDoc, paint))
+	want := "{\n  <\"name\">: \"a:b\",\n  <\"rows\">: [\"x\"]\n}\n"
+	if got != want {
+		t.Fatalf("json highlight = %q, want %q", got, want)
+	}
+
+	yamlDoc := []byte("name: a\nrows:\n  - col: 1\nnote: |\n  key: not-a-key\n")
+	got = string(yamlFormatter{}.HighlightKeys(yamlDoc, paint))
+	want = "<name>: a\n<rows>:\n  - <col>: 1\n<note>: |\n  key: not-a-key\n"
+	if got != want {
+		t.Fatalf("yaml highlight = %q, want %q", got, want)
+	}
+}
+
+type columnRows []map[string]any
+
+func (r columnRows) OutputColumns() []Column {
+	return []Column{{Name: "name"}, {Name: "status"}}
+}
+
+func TestTableFormat(t *testing.T) {
+	f, ok := Lookup("table")
+	if !ok {
+		t.Fatalf("expected table format registered")
+	}
+
+	buf := &bytes.Buffer{}
+	rows := []map[string]any{{"status": "ok", "name": "a"}, {"status": "failed", "name": "longer-name", "error": "line1\nline2"}}
+	if err := f.Format(buf, rows); err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	want := "ERROR        NAME         STATUS\n" +
+		"             a            ok\n" +
+		"line1 line2  longer-name  failed\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := f.Format(buf, columnRows(rows)); err != nil {
+		t.Fatalf("format: %v", err)
+	}
+	if lines := strings.Split(buf.String(), "\n"); lines[0] != "NAME         STATUS" || lines[1] != "a            ok" {
+		t.Fatalf("expected column order from provider, got:\n%s", buf.String())
+	}
+}
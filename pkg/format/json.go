@@ -0,0 +1,52 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Name() string        { return "json" }
+func (jsonFormatter) Description() string { return "Indented JSON document" }
+func (jsonFormatter) Streaming() bool     { return false }
+
+func (jsonFormatter) Format(w io.Writer, data interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+// HighlightKeys paints every object key (a string followed by a colon).
+func (jsonFormatter) HighlightKeys(doc []byte, paint func(string) string) []byte {
+	var out bytes.Buffer
+	for i := 0; i < len(doc); i++ {
+		if doc[i] != '"' {
+			out.WriteByte(doc[i])
+			continue
+		}
+		end := i + 1
+		for end < len(doc) && doc[end] != '"' {
+			if doc[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(doc) {
+			out.Write(doc[i:])
+			break
+		}
+		token := string(doc[i : end+1])
+		next := end + 1
+		for next < len(doc) && (doc[next] == ' ' || doc[next] == '\t') {
+			next++
+		}
+		if next < len(doc) && doc[next] == ':' {
+			token = paint(token)
+		}
+		out.WriteString(token)
+		i = end
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,198 @@
+package format
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+type separatedFormatter struct {
+	name        string
+	description string
+	sep         rune
+}
+
+func (f separatedFormatter) Name() string        { return f.name }
+func (f separatedFormatter) Description() string { return f.description }
+func (f separatedFormatter) Streaming() bool     { return true }
+
+// Format writes metadata (if any) as JSON followed by the rows as delimited records.
+func (f separatedFormatter) Format(w io.Writer, data interface{}) error {
+	meta, primary := SplitMetadata(data)
+	records, err := NormalizeRecords(primary)
+	if err != nil {
+		return err
+	}
+	if err := writeMetadata(w, meta); err != nil {
+		return err
+	}
+	return writeSeparated(w, records, f.sep)
+}
+
+// MetadataProvider allows callers to supply metadata separate from rows.
+type MetadataProvider interface {
+	OutputMetadata() (interface{}, interface{})
+}
+
+// NormalizeRecords converts arbitrary payloads into a list of flat records.
+func NormalizeRecords(data interface{}) ([]map[string]any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var anyData interface{}
+	if err := json.Unmarshal(raw, &anyData); err != nil {
+		return nil, err
+	}
+	return flattenAny(anyData)
+}
+
+// SplitMetadata separates metadata from the primary rows of a payload.
+func SplitMetadata(data interface{}) (interface{}, interface{}) {
+	if provider, ok := data.(MetadataProvider); ok {
+		return provider.OutputMetadata()
+	}
+	switch v := data.(type) {
+	case map[string]any:
+		return extractMetadata(v)
+	default:
+		return nil, data
+	}
+}
+
+func extractMetadata(m map[string]any) (map[string]any, interface{}) {
+	if rows, ok := m["rows"]; ok {
+		meta := copyMap(m)
+		delete(meta, "rows")
+		if len(meta) == 0 {
+			meta = nil
+		}
+		return meta, rows
+	}
+	return nil, m
+}
+
+func writeMetadata(out io.Writer, meta interface{}) error {
+	if meta == nil {
+		return nil
+	}
+	if m, ok := meta.(map[string]any); ok && len(m) == 0 {
+		return nil
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(meta); err != nil {
+		return err
+	}
+	if _, err := out.Write([]byte("\n")); err != nil {
+		return err
+	}
+	return nil
+}
+
+func flattenAny(value interface{}) ([]map[string]any, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		res := make([]map[string]any, 0, len(v))
+		for _, item := range v {
+			m, err := toMap(item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, m)
+		}
+		return res, nil
+	case map[string]interface{}:
+		if rows, ok := v["rows"].([]interface{}); ok {
+			base := copyMap(v)
+			delete(base, "rows")
+			res := make([]map[string]any, 0, len(rows)+1)
+			if len(base) > 0 {
+				res = append(res, base)
+			}
+			for _, row := range rows {
+				m, err := toMap(row)
+				if err != nil {
+					return nil, err
+				}
+				res = append(res, m)
+			}
+			return res, nil
+		}
+		m, err := toMap(v)
+		if err != nil {
+			return nil, err
+		}
+		return []map[string]any{m}, nil
+	default:
+		m, err := toMap(v)
+		if err != nil {
+			return nil, fmt.Errorf("data must be an object or array of objects for csv/tsv output")
+		}
+		return []map[string]any{m}, nil
+	}
+}
+
+func toMap(value interface{}) (map[string]any, error) {
+	if value == nil {
+		return map[string]any{}, nil
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, nil
+	default:
+		return map[string]any{"value": v}, nil
+	}
+}
+
+func copyMap(src map[string]any) map[string]any {
+	dst := make(map[string]any, len(src))
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+func writeSeparated(w io.Writer, records []map[string]any, sep rune) error {
+	if len(records) == 0 {
+		return nil
+	}
+	headers := collectHeaders(records)
+	writer := csv.NewWriter(w)
+	writer.Comma = sep
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	row := make([]string, len(headers))
+	for _, rec := range records {
+		for i, h := range headers {
+			if val, ok := rec[h]; ok && val != nil {
+				row[i] = fmt.Sprintf("%v", val)
+			} else {
+				row[i] = ""
+			}
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func collectHeaders(records []map[string]any) []string {
+	set := map[string]struct{}{}
+	for _, rec := range records {
+		for k := range rec {
+			set[k] = struct{}{}
+		}
+	}
+	headers := make([]string, 0, len(set))
+	for k := range set {
+		headers = append(headers, k)
+	}
+	sort.Strings(headers)
+	return headers
+}
@@ -0,0 +1,64 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// tableFormatter renders rows as space-aligned columns for reading in a
+// terminal. Column order follows ColumnProvider when the payload supplies it.
+type tableFormatter struct{}
+
+func (tableFormatter) Name() string        { return "table" }
+func (tableFormatter) Description() string { return "Aligned columns for terminals" }
+func (tableFormatter) Streaming() bool     { return true }
+
+// Format writes metadata (if any) as JSON followed by a header row and one
+// aligned line per record.
+func (tableFormatter) Format(w io.Writer, data interface{}) error {
+	meta, primary := SplitMetadata(data)
+	records, err := NormalizeRecords(primary)
+	if err != nil {
+		return err
+	}
+	if err := writeMetadata(w, meta); err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	var headers []string
+	if provider, ok := data.(ColumnProvider); ok {
+		for _, c := range provider.OutputColumns() {
+			headers = append(headers, c.Name)
+		}
+	}
+	if len(headers) == 0 {
+		headers = collectHeaders(records)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	row := make([]string, len(headers))
+	for i, h := range headers {
+		row[i] = strings.ToUpper(h)
+	}
+	fmt.Fprintln(tw, strings.Join(row, "\t"))
+	for _, rec := range records {
+		for i, h := range headers {
+			row[i] = ""
+			if val, ok := rec[h]; ok && val != nil {
+				row[i] = tableCell(fmt.Sprintf("%v", val))
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableCell keeps a value on one line so it cannot break the alignment.
+func tableCell(s string) string {
+	return strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ").Replace(s)
+}
@@ -0,0 +1,438 @@
+package format
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxFormatter writes an Office Open XML workbook with a "results" sheet of
+// typed cells and a "metadata" sheet of key/value pairs. Text cells of both
+// sheets share one string table, as Excel itself writes them.
+type xlsxFormatter struct{}
+
+func (xlsxFormatter) Name() string        { return "xlsx" }
+func (xlsxFormatter) Description() string { return "Excel workbook (requires --out-file)" }
+func (xlsxFormatter) Streaming() bool     { return false }
+func (xlsxFormatter) Binary() bool        { return true }
+
+const (
+	xlsxStyleDefault = iota
+	xlsxStyleDate
+	xlsxStyleDateTime
+)
+
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func (xlsxFormatter) Format(w io.Writer, data interface{}) error {
+	meta, primary := SplitMetadata(data)
+
+	records, ok := primary.([]map[string]any)
+	if !ok {
+		var err error
+		records, err = NormalizeRecords(primary)
+		if err != nil {
+			return err
+		}
+	}
+
+	var columns []Column
+	if provider, ok := data.(ColumnProvider); ok {
+		columns = provider.OutputColumns()
+	}
+	if len(columns) == 0 {
+		for _, h := range collectHeaders(records) {
+			columns = append(columns, Column{Name: h})
+		}
+	}
+
+	metaPairs, err := orderedFields(meta)
+	if err != nil {
+		return err
+	}
+
+	sst := &sharedStrings{index: map[string]int{}}
+	results := &bytes.Buffer{}
+	writeSheetStart(results)
+	headers := make([]xlsxCell, len(columns))
+	for i, col := range columns {
+		headers[i] = stringCell(col.Name)
+	}
+	writeRow(results, sst, 1, headers)
+	for r, rec := range records {
+		cells := make([]xlsxCell, len(columns))
+		for i, col := range columns {
+			cells[i] = typedCell(rec[col.Name], col.Type)
+		}
+		writeRow(results, sst, r+2, cells)
+	}
+	writeSheetEnd(results)
+
+	metadata := &bytes.Buffer{}
+	writeSheetStart(metadata)
+	writeRow(metadata, sst, 1, []xlsxCell{stringCell("key"), stringCell("value")})
+	for i, pair := range metaPairs {
+		writeRow(metadata, sst, i+2, []xlsxCell{stringCell(pair[0]), stringCell(pair[1])})
+	}
+	writeSheetEnd(metadata)
+
+	zw := zip.NewWriter(w)
+	parts := []struct {
+		name string
+		body []byte
+	}{
+		{"[Content_Types].xml", []byte(xlsxContentTypes)},
+		{"_rels/.rels", []byte(xlsxRootRels)},
+		{"xl/workbook.xml", []byte(xlsxWorkbook)},
+		{"xl/_rels/workbook.xml.rels", []byte(xlsxWorkbookRels)},
+		{"xl/styles.xml", []byte(xlsxStyles)},
+		{"xl/worksheets/sheet1.xml", results.Bytes()},
+		{"xl/worksheets/sheet2.xml", metadata.Bytes()},
+		{"xl/sharedStrings.xml", sst.bytes()},
+	}
+	for _, part := range parts {
+		fw, err := zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := fw.Write(part.body); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+type xlsxCell struct {
+	kind  string // "s" (shared string), "n" (number), "b" (boolean), or "" for empty
+	value string
+	style int
+}
+
+func stringCell(v string) xlsxCell {
+	return xlsxCell{kind: "s", value: v}
+}
+
+func numberCell(f float64) xlsxCell {
+	return xlsxCell{kind: "n", value: strconv.FormatFloat(f, 'f', -1, 64)}
+}
+
+func timeCell(t time.Time, style int) xlsxCell {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	serial := float64(wall.Sub(excelEpoch)) / float64(24*time.Hour)
+	return xlsxCell{kind: "n", value: strconv.FormatFloat(serial, 'f', -1, 64), style: style}
+}
+
+// typedCell converts a value into a cell using the Snowflake column type when
+// known, falling back to the Go type of the value.
+func typedCell(v any, colType string) xlsxCell {
+	if v == nil {
+		return xlsxCell{}
+	}
+	switch t := strings.ToUpper(colType); {
+	case t == "FIXED" || t == "REAL" || t == "NUMBER" || t == "DECIMAL" || t == "FLOAT" || t == "DOUBLE" || t == "INTEGER":
+		if f, ok := toFloat(v); ok {
+			return numberCell(f)
+		}
+	case t == "DATE":
+		if ts, ok := toTime(v); ok {
+			return timeCell(ts, xlsxStyleDate)
+		}
+	case strings.HasPrefix(t, "TIMESTAMP"):
+		if ts, ok := toTime(v); ok {
+			return timeCell(ts, xlsxStyleDateTime)
+		}
+	case t == "BOOLEAN":
+		if b, ok := toBool(v); ok {
+			return boolCell(b)
+		}
+	case t != "":
+		return stringCell(stringify(v))
+	}
+
+	switch val := v.(type) {
+	case bool:
+		return boolCell(val)
+	case time.Time:
+		return timeCell(val, xlsxStyleDateTime)
+	case string:
+		return stringCell(val)
+	}
+	if f, ok := toFloat(v); ok {
+		return numberCell(f)
+	}
+	return stringCell(stringify(v))
+}
+
+func boolCell(b bool) xlsxCell {
+	if b {
+		return xlsxCell{kind: "b", value: "1"}
+	}
+	return xlsxCell{kind: "b", value: "0"}
+}
+
+// toFloat converts v to a number cell value. Integers and decimal text that a
+// float64 cannot hold exactly, such as NUMBER(38,0) IDs above 2^53, are
+// rejected so they are written as text instead of being rounded.
+func toFloat(v any) (float64, bool) {
+	var f float64
+	exact := ""
+	switch val := v.(type) {
+	case float64:
+		f = val
+	case float32:
+		f = float64(val)
+	case int:
+		f = float64(val)
+		exact = strconv.Itoa(val)
+	case int32:
+		f = float64(val)
+	case int64:
+		f = float64(val)
+		exact = strconv.FormatInt(val, 10)
+	case uint64:
+		f = float64(val)
+		exact = strconv.FormatUint(val, 10)
+	case json.Number:
+		parsed, err := val.Float64()
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+		exact = val.String()
+	case string:
+		exact = strings.TrimSpace(val)
+		parsed, err := strconv.ParseFloat(exact, 64)
+		if err != nil {
+			return 0, false
+		}
+		f = parsed
+	default:
+		return 0, false
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, false
+	}
+	if exact != "" && !sameDecimal(exact, f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// sameDecimal reports whether the decimal text s denotes the same number as
+// the shortest decimal that round-trips f, i.e. no digits of s are lost.
+func sameDecimal(s string, f float64) bool {
+	want, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return false
+	}
+	got, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return ok && want.Cmp(got) == 0
+}
+
+func toTime(v any) (time.Time, bool) {
+	switch val := v.(type) {
+	case time.Time:
+		return val, true
+	case string:
+		trimmed := strings.TrimSpace(val)
+		for _, layout := range timestampLayouts {
+			if ts, err := time.Parse(layout, trimmed); err == nil {
+				return ts, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func toBool(v any) (bool, bool) {
+	switch val := v.(type) {
+	case bool:
+		return val, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(val))
+		return b, err == nil
+	}
+	return false, false
+}
+
+func stringify(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case map[string]any, []any:
+		raw, err := json.Marshal(val)
+		if err == nil {
+			return string(raw)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// orderedFields flattens metadata into key/value pairs, keeping the field
+// order of the JSON encoding.
+func orderedFields(meta interface{}) ([][2]string, error) {
+	if meta == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return [][2]string{{"value", string(raw)}}, nil
+	}
+	var pairs [][2]string
+	for dec.More() {
+		keyTok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		var str string
+		if err := json.Unmarshal(value, &str); err != nil {
+			str = string(value)
+		}
+		pairs = append(pairs, [2]string{fmt.Sprint(keyTok), str})
+	}
+	return pairs, nil
+}
+
+func columnName(idx int) string {
+	name := ""
+	for idx >= 0 {
+		name = string(rune('A'+idx%26)) + name
+		idx = idx/26 - 1
+	}
+	return name
+}
+
+func writeSheetStart(buf *bytes.Buffer) {
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+}
+
+func writeSheetEnd(buf *bytes.Buffer) {
+	buf.WriteString(`</sheetData></worksheet>`)
+}
+
+// sharedStrings is the workbook's string table. Each distinct text is stored
+// once and cells refer to it by index.
+type sharedStrings struct {
+	index  map[string]int
+	values []string
+	count  int
+}
+
+func (s *sharedStrings) add(v string) int {
+	s.count++
+	if idx, ok := s.index[v]; ok {
+		return idx
+	}
+	idx := len(s.values)
+	s.index[v] = idx
+	s.values = append(s.values, v)
+	return idx
+}
+
+func (s *sharedStrings) bytes() []byte {
+	buf := &bytes.Buffer{}
+	buf.WriteString(xml.Header)
+	fmt.Fprintf(buf, `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" count="%d" uniqueCount="%d">`, s.count, len(s.values))
+	for _, v := range s.values {
+		buf.WriteString(`<si><t xml:space="preserve">`)
+		_ = xml.EscapeText(buf, []byte(v))
+		buf.WriteString(`</t></si>`)
+	}
+	buf.WriteString(`</sst>`)
+	return buf.Bytes()
+}
+
+func writeRow(buf *bytes.Buffer, sst *sharedStrings, rowNum int, cells []xlsxCell) {
+	fmt.Fprintf(buf, `<row r="%d">`, rowNum)
+	for i, cell := range cells {
+		if cell.kind == "" {
+			continue
+		}
+		ref := fmt.Sprintf("%s%d", columnName(i), rowNum)
+		style := ""
+		if cell.style != xlsxStyleDefault {
+			style = fmt.Sprintf(` s="%d"`, cell.style)
+		}
+		switch cell.kind {
+		case "s":
+			fmt.Fprintf(buf, `<c r="%s" t="s"%s><v>%d</v></c>`, ref, style, sst.add(cell.value))
+		case "b":
+			fmt.Fprintf(buf, `<c r="%s" t="b"%s><v>%s</v></c>`, ref, style, cell.value)
+		default:
+			fmt.Fprintf(buf, `<c r="%s"%s><v>%s</v></c>`, ref, style, cell.value)
+		}
+	}
+	buf.WriteString(`</row>`)
+}
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/sharedStrings.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sharedStrings+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets><sheet name="results" sheetId="1" r:id="rId1"/><sheet name="metadata" sheetId="2" r:id="rId2"/></sheets>` +
+	`</workbook>`
+
+const xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines cell formats indexed by the xlsxStyle* constants:
+// 0 general, 1 built-in date (numFmt 14), 2 built-in date-time (numFmt 22).
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="3">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="22" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`</styleSheet>`
@@ -0,0 +1,142 @@
+package format
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+type xlsxPayload struct {
+	Rows []map[string]any
+}
+
+func (p xlsxPayload) OutputMetadata() (interface{}, interface{}) {
+	return struct {
+		Connection string `json:"connection"`
+		QueryID    string `json:"queryId"`
+	}{"prod", "01b2-abc"}, p.Rows
+}
+
+func (p xlsxPayload) OutputColumns() []Column {
+	return []Column{
+		{Name: "NAME", Type: "TEXT"},
+		{Name: "AMOUNT", Type: "FIXED"},
+		{Name: "BOOKED", Type: "DATE"},
+	}
+}
+
+func readZipPart(t *testing.T, data []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("open workbook: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		defer rc.Close()
+		body, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+		return string(body)
+	}
+	t.Fatalf("workbook missing part %s", name)
+	return ""
+}
+
+func TestXLSXWritesTypedCellsAndMetadata(t *testing.T) {
+	f, ok := Lookup("xlsx")
+	if !ok || !IsBinary(f) {
+		t.Fatalf("expected binary xlsx formatter registered")
+	}
+
+	payload := xlsxPayload{Rows: []map[string]any{
+		{"NAME": "a<b", "AMOUNT": "12.50", "BOOKED": time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}}
+	buf := &bytes.Buffer{}
+	if err := f.Format(buf, payload); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+
+	results := readZipPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml")
+	if !strings.Contains(results, `<c r="A2" t="s"><v>3</v></c>`) {
+		t.Fatalf("expected shared string cell, got %s", results)
+	}
+	if !strings.Contains(results, `<c r="B2"><v>12.5</v></c>`) {
+		t.Fatalf("expected numeric cell, got %s", results)
+	}
+	if !strings.Contains(results, `<c r="C2" s="1"><v>45659</v></c>`) {
+		t.Fatalf("expected date serial cell, got %s", results)
+	}
+
+	sst := readZipPart(t, buf.Bytes(), "xl/sharedStrings.xml")
+	for _, want := range []string{
+		`count="10" uniqueCount="10"`,
+		`<si><t xml:space="preserve">NAME</t></si>`,
+		`<si><t xml:space="preserve">a&lt;b</t></si>`,
+		`<si><t xml:space="preserve">queryId</t></si><si><t xml:space="preserve">01b2-abc</t></si>`,
+	} {
+		if !strings.Contains(sst, want) {
+			t.Fatalf("expected %s in shared strings, got %s", want, sst)
+		}
+	}
+	metadata := readZipPart(t, buf.Bytes(), "xl/worksheets/sheet2.xml")
+	if !strings.Contains(metadata, `<c r="A3" t="s"><v>8</v></c><c r="B3" t="s"><v>9</v></c>`) {
+		t.Fatalf("expected query id on metadata sheet, got %s", metadata)
+	}
+	workbook := readZipPart(t, buf.Bytes(), "xl/workbook.xml")
+	if !strings.Contains(workbook, `name="metadata"`) {
+		t.Fatalf("expected metadata sheet, got %s", workbook)
+	}
+}
+
+func TestXLSXKeepsLargeNumbersExact(t *testing.T) {
+	cases := []struct {
+		value any
+		want  xlsxCell
+	}{
+		{"12345678901234567891", stringCell("12345678901234567891")},
+		{json.Number("99999999999999999999999999999999999999"), stringCell("99999999999999999999999999999999999999")},
+		{int64(1<<62 + 1), stringCell("4611686018427387905")},
+		{"9007199254740992", numberCell(9007199254740992)},
+		{"12.50", numberCell(12.5)},
+		{"0.1", numberCell(0.1)},
+		{"-1e3", numberCell(-1000)},
+	}
+	for _, tc := range cases {
+		if got := typedCell(tc.value, "FIXED"); got != tc.want {
+			t.Fatalf("typedCell(%v) = %+v, want %+v", tc.value, got, tc.want)
+		}
+	}
+
+	payload := xlsxPayload{Rows: []map[string]any{{"NAME": "id", "AMOUNT": "12345678901234567891"}}}
+	buf := &bytes.Buffer{}
+	if err := (xlsxFormatter{}).Format(buf, payload); err != nil {
+		t.Fatalf("Format: %v", err)
+	}
+	if results := readZipPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"); !strings.Contains(results, `<c r="B2" t="s">`) {
+		t.Fatalf("expected the large FIXED value as a text cell, got %s", results)
+	}
+	if sst := readZipPart(t, buf.Bytes(), "xl/sharedStrings.xml"); !strings.Contains(sst, ">12345678901234567891<") {
+		t.Fatalf("expected every digit kept, got %s", sst)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	cases := map[int]string{0: "A", 25: "Z", 26: "AA", 701: "ZZ", 702: "AAA"}
+	for idx, want := range cases {
+		if got := columnName(idx); got != want {
+			t.Fatalf("columnName(%d) = %s, want %s", idx, got, want)
+		}
+	}
+}
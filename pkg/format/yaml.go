@@ -0,0 +1,54 @@
+package format
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) Name() string        { return "yaml" }
+func (yamlFormatter) Description() string { return "YAML document" }
+func (yamlFormatter) Streaming() bool     { return false }
+
+func (yamlFormatter) Format(w io.Writer, data interface{}) error {
+	buf, err := yaml.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(buf); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte("\n"))
+	return err
+}
+
+var yamlKeyPattern = regexp.MustCompile(`^(\s*(?:- )*)("[^"]*"|'[^']*'|[^\s#'"\-][^:]*?|-[^\s:][^:]*?):(\s|$)`)
+
+// HighlightKeys paints mapping keys line by line, leaving block scalar bodies untouched.
+func (yamlFormatter) HighlightKeys(doc []byte, paint func(string) string) []byte {
+	lines := strings.Split(string(doc), "\n")
+	blockIndent := -1
+	for i, line := range lines {
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if blockIndent >= 0 {
+			if strings.TrimSpace(line) == "" || indent > blockIndent {
+				continue
+			}
+			blockIndent = -1
+		}
+		m := yamlKeyPattern.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		value := strings.TrimSpace(line[m[1]:])
+		if strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">") {
+			blockIndent = indent
+		}
+		lines[i] = line[:m[4]] + paint(line[m[4]:m[5]]) + line[m[5]:]
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
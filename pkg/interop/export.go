@@ -0,0 +1,245 @@
+package interop
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+const (
+	// ExportSnowCLI renders Snowflake CLI connections.toml tables.
+	ExportSnowCLI = "snowcli"
+	// ExportSnowSQL renders SnowSQL [connections.NAME] sections.
+	ExportSnowSQL = "snowsql"
+	// ExportDBT renders a dbt profiles.yml profile with one target per connection.
+	ExportDBT = "dbt"
+	// ExportEnv renders SNOWFLAKE_* variables for a single connection.
+	ExportEnv = "env"
+)
+
+// ExportFormats lists the supported export formats.
+var ExportFormats = []string{ExportSnowCLI, ExportSnowSQL, ExportDBT, ExportEnv}
+
+// ExportOptions controls how connections are rendered.
+type ExportOptions struct {
+	// IncludeSecrets writes stored passwords/PATs in plain text. When false,
+	// secrets are omitted (dbt targets reference SNOWFLAKE_PASSWORD instead).
+	IncludeSecrets bool
+	// DBTProfile names the dbt profile; defaults to "snowctl".
+	DBTProfile string
+}
+
+// Export renders contexts in the requested format.
+func Export(format string, contexts []*config.Context, opts ExportOptions) ([]byte, error) {
+	if len(contexts) == 0 {
+		return nil, fmt.Errorf("no connections to export")
+	}
+	switch format {
+	case ExportSnowCLI:
+		return exportSnowCLI(contexts, opts)
+	case ExportSnowSQL:
+		return exportSnowSQL(contexts, opts), nil
+	case ExportDBT:
+		return exportDBT(contexts, opts)
+	case ExportEnv:
+		if len(contexts) != 1 {
+			return nil, fmt.Errorf("env export supports exactly one connection, got %d", len(contexts))
+		}
+		return exportEnv(contexts[0], opts), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q (supported: %s)", format, strings.Join(ExportFormats, ", "))
+	}
+}
+
+// customHost returns the configured host, or the host of the account URL when
+// it differs from the one Snowflake derives from the account identifier (e.g.
+// PrivateLink).
+func customHost(ctx *config.Context) string {
+	if ctx.Host != "" {
+		return ctx.Host
+	}
+	if ctx.AccountURL == "" || ctx.AccountURL == accountURL("", ctx.Account) {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(ctx.AccountURL, "https://"), "http://")
+	return strings.TrimSuffix(host, "/")
+}
+
+func exportSnowCLI(contexts []*config.Context, opts ExportOptions) ([]byte, error) {
+	tables := map[string]map[string]any{}
+	for _, ctx := range contexts {
+		t := map[string]any{}
+		putNonEmpty(t, "account", ctx.Account)
+		putNonEmpty(t, "user", ctx.User)
+		putNonEmpty(t, "role", ctx.Role)
+		putNonEmpty(t, "warehouse", ctx.Warehouse)
+		putNonEmpty(t, "database", ctx.Database)
+		putNonEmpty(t, "schema", ctx.Schema)
+		putNonEmpty(t, "host", customHost(ctx))
+		if ctx.Port != 0 {
+			t["port"] = ctx.Port
+		}
+		if ctx.AuthMethod == "pat" {
+			t["authenticator"] = "PROGRAMMATIC_ACCESS_TOKEN"
+			if opts.IncludeSecrets {
+				putNonEmpty(t, "token", ctx.Secret)
+			}
+		} else if opts.IncludeSecrets {
+			putNonEmpty(t, "password", ctx.Secret)
+		}
+		tables[ctx.Name] = t
+	}
+	return toml.Marshal(tables)
+}
+
+func exportSnowSQL(contexts []*config.Context, opts ExportOptions) []byte {
+	buf := &bytes.Buffer{}
+	for i, ctx := range contexts {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(buf, "[connections.%s]\n", ctx.Name)
+		writeINI(buf, "accountname", ctx.Account)
+		writeINI(buf, "username", ctx.User)
+		writeINI(buf, "rolename", ctx.Role)
+		writeINI(buf, "warehousename", ctx.Warehouse)
+		writeINI(buf, "dbname", ctx.Database)
+		writeINI(buf, "schemaname", ctx.Schema)
+		writeINI(buf, "host", customHost(ctx))
+		if ctx.Port != 0 {
+			writeINI(buf, "port", strconv.Itoa(ctx.Port))
+		}
+		if ctx.AuthMethod == "pat" {
+			writeINI(buf, "authenticator", "PROGRAMMATIC_ACCESS_TOKEN")
+			if opts.IncludeSecrets {
+				writeINI(buf, "token", ctx.Secret)
+			}
+		} else if opts.IncludeSecrets {
+			writeINI(buf, "password", ctx.Secret)
+		}
+	}
+	return buf.Bytes()
+}
+
+func writeINI(buf *bytes.Buffer, key, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(buf, "%s = %s\n", key, quoteINI(value))
+}
+
+// quoteINI quotes values that SnowSQL's configobj parser would otherwise
+// split on commas, cut at an inline comment, or trim.
+func quoteINI(value string) string {
+	if !strings.ContainsAny(value, `#;,"'`) && strings.TrimSpace(value) == value {
+		return value
+	}
+	switch {
+	case !strings.Contains(value, `"`):
+		return `"` + value + `"`
+	case !strings.Contains(value, "'"):
+		return "'" + value + "'"
+	default:
+		return `"""` + value + `"""`
+	}
+}
+
+type dbtProfile struct {
+	Target  string                `yaml:"target"`
+	Outputs map[string]*dbtTarget `yaml:"outputs"`
+}
+
+type dbtTarget struct {
+	Type          string `yaml:"type"`
+	Account       string `yaml:"account"`
+	User          string `yaml:"user,omitempty"`
+	Password      string `yaml:"password,omitempty"`
+	Authenticator string `yaml:"authenticator,omitempty"`
+	Token         string `yaml:"token,omitempty"`
+	Role          string `yaml:"role,omitempty"`
+	Warehouse     string `yaml:"warehouse,omitempty"`
+	Database      string `yaml:"database,omitempty"`
+	Schema        string `yaml:"schema,omitempty"`
+	Host          string `yaml:"host,omitempty"`
+	Port          int    `yaml:"port,omitempty"`
+	Threads       int    `yaml:"threads"`
+}
+
+func exportDBT(contexts []*config.Context, opts ExportOptions) ([]byte, error) {
+	profileName := strings.TrimSpace(opts.DBTProfile)
+	if profileName == "" {
+		profileName = "snowctl"
+	}
+	profile := &dbtProfile{Target: contexts[0].Name, Outputs: map[string]*dbtTarget{}}
+	for _, ctx := range contexts {
+		target := &dbtTarget{
+			Type:      "snowflake",
+			Account:   ctx.Account,
+			User:      ctx.User,
+			Role:      ctx.Role,
+			Warehouse: ctx.Warehouse,
+			Database:  ctx.Database,
+			Schema:    ctx.Schema,
+			Host:      customHost(ctx),
+			Port:      ctx.Port,
+			Threads:   4,
+		}
+		secret := "{{ env_var('SNOWFLAKE_PASSWORD') }}"
+		if opts.IncludeSecrets {
+			secret = ctx.Secret
+		}
+		if ctx.AuthMethod == "pat" {
+			target.Authenticator = "programmatic_access_token"
+			target.Token = secret
+		} else {
+			target.Password = secret
+		}
+		profile.Outputs[ctx.Name] = target
+	}
+	return yaml.Marshal(map[string]*dbtProfile{profileName: profile})
+}
+
+func exportEnv(ctx *config.Context, opts ExportOptions) []byte {
+	buf := &bytes.Buffer{}
+	writeEnv(buf, "SNOWFLAKE_ACCOUNT", ctx.Account)
+	writeEnv(buf, "SNOWFLAKE_ACCOUNT_URL", ctx.AccountURL)
+	writeEnv(buf, "SNOWFLAKE_HOST", ctx.Host)
+	if ctx.Port != 0 {
+		writeEnv(buf, "SNOWFLAKE_PORT", strconv.Itoa(ctx.Port))
+	}
+	writeEnv(buf, "SNOWFLAKE_USER", ctx.User)
+	writeEnv(buf, "SNOWFLAKE_ROLE", ctx.Role)
+	writeEnv(buf, "SNOWFLAKE_WAREHOUSE", ctx.Warehouse)
+	writeEnv(buf, "SNOWFLAKE_DATABASE", ctx.Database)
+	writeEnv(buf, "SNOWFLAKE_SCHEMA", ctx.Schema)
+	if ctx.AuthMethod == "pat" {
+		writeEnv(buf, "SNOWFLAKE_AUTHENTICATOR", "PROGRAMMATIC_ACCESS_TOKEN")
+	}
+	if opts.IncludeSecrets {
+		writeEnv(buf, "SNOWFLAKE_PASSWORD", ctx.Secret)
+	}
+	return buf.Bytes()
+}
+
+func writeEnv(buf *bytes.Buffer, key, value string) {
+	if value == "" {
+		return
+	}
+	if strings.ContainsAny(value, " \t\"'$#\\`\n") {
+		escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`", "\n", `\n`).Replace(value)
+		value = `"` + escaped + `"`
+	}
+	fmt.Fprintf(buf, "%s=%s\n", key, value)
+}
+
+func putNonEmpty(m map[string]any, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
@@ -0,0 +1,138 @@
+// Package interop converts snowctl connections to and from the configuration
+// formats of other Snowflake tools (SnowSQL, Snowflake CLI).
+package interop
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+const (
+	// SourceSnowSQL reads ~/.snowsql/config.
+	SourceSnowSQL = "snowsql"
+	// SourceSnowCLI reads ~/.snowflake/connections.toml.
+	SourceSnowCLI = "snowcli"
+)
+
+// Sources lists the supported import formats.
+var Sources = []string{SourceSnowSQL, SourceSnowCLI}
+
+// ImportedConnection is a connection parsed from another tool's config.
+type ImportedConnection struct {
+	Context *config.Context
+	// Skipped explains why the connection cannot be used by snowctl, e.g. an
+	// unsupported authenticator. Empty when the connection is importable.
+	Skipped string
+}
+
+// ValidateSource reports an error when source is not one of Sources.
+func ValidateSource(source string) error {
+	if slices.Contains(Sources, source) {
+		return nil
+	}
+	return fmt.Errorf("unsupported import source %q (supported: %s)", source, strings.Join(Sources, ", "))
+}
+
+// DefaultPath returns the conventional config location for the given source.
+func DefaultPath(source string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("determine home directory: %w", err)
+	}
+	switch source {
+	case SourceSnowSQL:
+		return filepath.Join(home, ".snowsql", "config"), nil
+	case SourceSnowCLI:
+		if dir := strings.TrimSpace(os.Getenv("SNOWFLAKE_HOME")); dir != "" {
+			return filepath.Join(dir, "connections.toml"), nil
+		}
+		return filepath.Join(home, ".snowflake", "connections.toml"), nil
+	default:
+		return "", ValidateSource(source)
+	}
+}
+
+// Parse reads connections of the given source format from data.
+func Parse(source string, data []byte) ([]ImportedConnection, error) {
+	switch source {
+	case SourceSnowSQL:
+		return ParseSnowSQL(data)
+	case SourceSnowCLI:
+		return ParseSnowCLI(data)
+	default:
+		return nil, ValidateSource(source)
+	}
+}
+
+// authFromAuthenticator maps a Snowflake authenticator name onto snowctl auth
+// methods. It returns "" when snowctl cannot use the authenticator.
+func authFromAuthenticator(authenticator string) string {
+	switch strings.ToLower(strings.TrimSpace(authenticator)) {
+	case "", "snowflake":
+		return "password"
+	case "programmatic_access_token":
+		return "pat"
+	default:
+		return ""
+	}
+}
+
+// accountURL derives the account URL from an explicit host or the account identifier.
+func accountURL(host, account string) string {
+	host = strings.TrimSpace(host)
+	if host != "" {
+		if strings.HasPrefix(host, "https://") || strings.HasPrefix(host, "http://") {
+			return host
+		}
+		return "https://" + host
+	}
+	if account == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s.snowflakecomputing.com", strings.ToLower(account))
+}
+
+// newImported builds an ImportedConnection from generic key/values shared by both formats.
+func newImported(name string, fields map[string]string) ImportedConnection {
+	ctx := &config.Context{
+		Name:      name,
+		Account:   fields["account"],
+		User:      fields["user"],
+		Role:      fields["role"],
+		Warehouse: fields["warehouse"],
+		Database:  fields["database"],
+		Schema:    fields["schema"],
+	}
+	ctx.AccountURL = accountURL(fields["host"], ctx.Account)
+
+	res := ImportedConnection{Context: ctx}
+	method := authFromAuthenticator(fields["authenticator"])
+	switch {
+	case method == "":
+		res.Skipped = fmt.Sprintf("authenticator %q is not supported (use password or PAT)", fields["authenticator"])
+	case method == "pat":
+		ctx.AuthMethod = method
+		ctx.Secret = firstNonEmpty(fields["token"], fields["password"])
+	default:
+		ctx.AuthMethod = method
+		ctx.Secret = fields["password"]
+	}
+	if res.Skipped == "" && ctx.Account == "" {
+		res.Skipped = "no account configured"
+	}
+	return res
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,191 @@
+package interop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+func TestParseSnowSQL(t *testing.T) {
+	data := []byte(`
+[options]
+log_level = DEBUG
+
+[connections]
+accountname = base-acct
+username = base
+password = "pw0"
+
+[connections.prod]
+accountname = myorg-prod
+username = jsmith
+password = 'secret'
+rolename = ANALYST
+warehousename = REPORTING_WH
+dbname = FINANCE
+schemaname = PUBLIC
+
+[connections.sso]
+accountname = myorg-sso
+authenticator = externalbrowser
+`)
+	conns, err := ParseSnowSQL(data)
+	if err != nil {
+		t.Fatalf("ParseSnowSQL: %v", err)
+	}
+	if len(conns) != 3 {
+		t.Fatalf("expected 3 connections, got %d", len(conns))
+	}
+	byName := map[string]ImportedConnection{}
+	for _, c := range conns {
+		byName[c.Context.Name] = c
+	}
+	prod := byName["prod"].Context
+	if prod.Account != "myorg-prod" || prod.User != "jsmith" || prod.Secret != "secret" || prod.Role != "ANALYST" ||
+		prod.Warehouse != "REPORTING_WH" || prod.Database != "FINANCE" || prod.Schema != "PUBLIC" || prod.AuthMethod != "password" {
+		t.Fatalf("unexpected prod mapping: %+v", prod)
+	}
+	if prod.AccountURL != "https://myorg-prod.snowflakecomputing.com" {
+		t.Fatalf("unexpected account url %s", prod.AccountURL)
+	}
+	if byName[SnowSQLDefaultName].Context.Secret != "pw0" {
+		t.Fatalf("expected default section imported")
+	}
+	if byName["sso"].Skipped == "" {
+		t.Fatalf("expected externalbrowser connection to be skipped")
+	}
+}
+
+func TestParseSnowCLI(t *testing.T) {
+	data := []byte(`
+[dev]
+account = "myorg-dev"
+user = "dev"
+password = "pw"
+warehouse = "DEV_WH"
+
+[pat]
+account = "myorg-pat"
+user = "bot"
+authenticator = "PROGRAMMATIC_ACCESS_TOKEN"
+token = "tok"
+host = "myorg-pat.privatelink.snowflakecomputing.com"
+`)
+	conns, err := ParseSnowCLI(data)
+	if err != nil {
+		t.Fatalf("ParseSnowCLI: %v", err)
+	}
+	if len(conns) != 2 || conns[0].Context.Name != "dev" || conns[1].Context.Name != "pat" {
+		t.Fatalf("unexpected connections: %+v", conns)
+	}
+	pat := conns[1].Context
+	if pat.AuthMethod != "pat" || pat.Secret != "tok" || pat.AccountURL != "https://myorg-pat.privatelink.snowflakecomputing.com" {
+		t.Fatalf("unexpected pat mapping: %+v", pat)
+	}
+
+	nested, err := ParseSnowCLI([]byte("[connections.alt]\naccount = \"a\"\npassword = \"p\"\n"))
+	if err != nil || len(nested) != 1 || nested[0].Context.Name != "alt" {
+		t.Fatalf("expected config.toml style connections, got %+v (%v)", nested, err)
+	}
+}
+
+func TestExportOmitsSecretsByDefault(t *testing.T) {
+	ctx := &config.Context{Name: "prod", Account: "myorg-prod", User: "jsmith", Role: "ANALYST", AuthMethod: "password", Secret: "s3cret"}
+	for _, format := range ExportFormats {
+		data, err := Export(format, []*config.Context{ctx}, ExportOptions{})
+		if err != nil {
+			t.Fatalf("Export %s: %v", format, err)
+		}
+		if strings.Contains(string(data), "s3cret") {
+			t.Fatalf("%s export leaked secret: %s", format, data)
+		}
+		if !strings.Contains(string(data), "myorg-prod") {
+			t.Fatalf("%s export missing account: %s", format, data)
+		}
+	}
+
+	dbt, _ := Export(ExportDBT, []*config.Context{ctx}, ExportOptions{})
+	if !strings.Contains(string(dbt), "env_var(''SNOWFLAKE_PASSWORD'')") {
+		t.Fatalf("expected dbt password placeholder, got %s", dbt)
+	}
+}
+
+func TestExportRoundTripsThroughImport(t *testing.T) {
+	ctx := &config.Context{Name: "prod", Account: "myorg-prod", User: "jsmith", Warehouse: "WH", AuthMethod: "pat", Secret: "tok"}
+
+	data, err := Export(ExportSnowCLI, []*config.Context{ctx}, ExportOptions{IncludeSecrets: true})
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	conns, err := ParseSnowCLI(data)
+	if err != nil || len(conns) != 1 {
+		t.Fatalf("ParseSnowCLI: %v (%d)", err, len(conns))
+	}
+	got := conns[0].Context
+	if got.Account != ctx.Account || got.Warehouse != ctx.Warehouse || got.AuthMethod != "pat" || got.Secret != "tok" {
+		t.Fatalf("round trip mismatch: %+v", got)
+	}
+
+	sql, _ := Export(ExportSnowSQL, []*config.Context{ctx}, ExportOptions{IncludeSecrets: true})
+	conns, err = ParseSnowSQL(sql)
+	if err != nil || len(conns) != 1 {
+		t.Fatalf("snowsql round trip failed: %+v (%v)", conns, err)
+	}
+	if got := conns[0].Context; got.AuthMethod != "pat" || got.Secret != "tok" {
+		t.Fatalf("snowsql round trip mismatch: %+v", got)
+	}
+}
+
+func TestExportSnowSQLQuotesValues(t *testing.T) {
+	for _, secret := range []string{"pa#ss", "a,b", " lead", `say "hi"`, `it's "both"`} {
+		ctx := &config.Context{Name: "prod", Account: "myorg-prod", AuthMethod: "password", Secret: secret}
+		data, err := Export(ExportSnowSQL, []*config.Context{ctx}, ExportOptions{IncludeSecrets: true})
+		if err != nil {
+			t.Fatalf("Export: %v", err)
+		}
+		conns, err := ParseSnowSQL(data)
+		if err != nil || len(conns) != 1 {
+			t.Fatalf("ParseSnowSQL(%s): %v", data, err)
+		}
+		if got := conns[0].Context; got.AuthMethod != "password" || got.Secret != secret {
+			t.Fatalf("secret %q round tripped as %+v from %s", secret, got, data)
+		}
+	}
+}
+
+func TestExportIncludesHostAndPort(t *testing.T) {
+	ctx := &config.Context{Name: "pl", Account: "myorg-prod", Host: "myorg-prod.privatelink.snowflakecomputing.com", Port: 8443, AuthMethod: "password"}
+	want := map[string][]string{
+		ExportSnowCLI: {`host = 'myorg-prod.privatelink.snowflakecomputing.com'`, `port = 8443`},
+		ExportSnowSQL: {`host = myorg-prod.privatelink.snowflakecomputing.com`, `port = 8443`},
+		ExportDBT:     {`host: myorg-prod.privatelink.snowflakecomputing.com`, `port: 8443`},
+		ExportEnv:     {`SNOWFLAKE_HOST=myorg-prod.privatelink.snowflakecomputing.com`, `SNOWFLAKE_PORT=8443`},
+	}
+	for format, lines := range want {
+		data, err := Export(format, []*config.Context{ctx}, ExportOptions{})
+		if err != nil {
+			t.Fatalf("Export %s: %v", format, err)
+		}
+		for _, line := range lines {
+			if !strings.Contains(string(data), line) {
+				t.Fatalf("%s export missing %q: %s", format, line, data)
+			}
+		}
+	}
+}
+
+func TestExportEnvRequiresSingleConnection(t *testing.T) {
+	a := &config.Context{Name: "a", Account: "a"}
+	b := &config.Context{Name: "b", Account: "b"}
+	if _, err := Export(ExportEnv, []*config.Context{a, b}, ExportOptions{}); err == nil {
+		t.Fatalf("expected env export to reject multiple connections")
+	}
+	data, err := Export(ExportEnv, []*config.Context{{Name: "a", Account: "a", Secret: "p w$"}}, ExportOptions{IncludeSecrets: true})
+	if err != nil {
+		t.Fatalf("Export env: %v", err)
+	}
+	if !strings.Contains(string(data), `SNOWFLAKE_PASSWORD="p w\$"`) {
+		t.Fatalf("expected quoted password, got %s", data)
+	}
+}
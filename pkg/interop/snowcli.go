@@ -0,0 +1,40 @@
+package interop
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ParseSnowCLI reads Snowflake CLI connections. Both connections.toml (one
+// table per connection) and config.toml ([connections.NAME] tables) are accepted.
+func ParseSnowCLI(data []byte) ([]ImportedConnection, error) {
+	tree := map[string]any{}
+	if err := toml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("parse snowflake cli config: %w", err)
+	}
+	if nested, ok := tree["connections"].(map[string]any); ok {
+		tree = nested
+	}
+
+	names := make([]string, 0, len(tree))
+	for name, v := range tree {
+		if _, ok := v.(map[string]any); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	res := make([]ImportedConnection, 0, len(names))
+	for _, name := range names {
+		table := tree[name].(map[string]any)
+		fields := map[string]string{}
+		for k, v := range table {
+			fields[strings.ToLower(k)] = fmt.Sprint(v)
+		}
+		res = append(res, newImported(name, fields))
+	}
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package interop
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// snowsqlKeys maps SnowSQL config keys onto the generic field names used by newImported.
+var snowsqlKeys = map[string]string{
+	"accountname":   "account",
+	"account":       "account",
+	"username":      "user",
+	"user":          "user",
+	"password":      "password",
+	"token":         "token",
+	"rolename":      "role",
+	"role":          "role",
+	"warehousename": "warehouse",
+	"warehouse":     "warehouse",
+	"dbname":        "database",
+	"database":      "database",
+	"schemaname":    "schema",
+	"schema":        "schema",
+	"authenticator": "authenticator",
+	"host":          "host",
+}
+
+// SnowSQLDefaultName is the snowctl name given to SnowSQL's unnamed [connections] section.
+const SnowSQLDefaultName = "default"
+
+// ParseSnowSQL reads the INI-style ~/.snowsql/config. The bare [connections]
+// section becomes "default"; [connections.NAME] sections keep their names.
+func ParseSnowSQL(data []byte) ([]ImportedConnection, error) {
+	sections := map[string]map[string]string{}
+	var current map[string]string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("snowsql config line %d: malformed section header %q", lineNo, line)
+			}
+			header := strings.TrimSpace(line[1 : len(line)-1])
+			current = nil
+			switch {
+			case header == "connections":
+				current = ensureSection(sections, SnowSQLDefaultName)
+			case strings.HasPrefix(header, "connections."):
+				current = ensureSection(sections, strings.TrimPrefix(header, "connections."))
+			}
+			continue
+		}
+		if current == nil {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("snowsql config line %d: expected key = value", lineNo)
+		}
+		field, known := snowsqlKeys[strings.ToLower(strings.TrimSpace(key))]
+		if !known {
+			continue
+		}
+		current[field] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read snowsql config: %w", err)
+	}
+
+	names := make([]string, 0, len(sections))
+	for name := range sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	res := make([]ImportedConnection, 0, len(names))
+	for _, name := range names {
+		res = append(res, newImported(name, sections[name]))
+	}
+	return res, nil
+}
+
+func ensureSection(sections map[string]map[string]string, name string) map[string]string {
+	if s, ok := sections[name]; ok {
+		return s
+	}
+	s := map[string]string{}
+	sections[name] = s
+	return s
+}
+
+func unquote(v string) string {
+	for _, q := range []string{`"""`, "'''"} {
+		if len(v) >= 2*len(q) && strings.HasPrefix(v, q) && strings.HasSuffix(v, q) {
+			return v[len(q) : len(v)-len(q)]
+		}
+	}
+	if len(v) >= 2 {
+		if (v[0] == '"' && v[len(v)-1] == '"') || (v[0] == '\'' && v[len(v)-1] == '\'') {
+			return v[1 : len(v)-1]
+		}
+	}
+	return v
+}
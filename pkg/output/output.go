@@ -1,16 +1,16 @@
 package output
 
 import (
-	"encoding/csv"
-	"encoding/json"
+	"bytes"
 	"fmt"
 	"io"
-	"sort"
+	"os"
 
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/runtime"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/style"
 )
 
 // Print renders data according to the runtime output format.
@@ -20,208 +20,51 @@ func Print(cmd *cobra.Command, data interface{}) error {
 		return err
 	}
 
-	out := cmd.OutOrStdout()
-
-	switch rt.OutputFormat {
-	case "json":
-		enc := json.NewEncoder(out)
-		enc.SetIndent("", "  ")
-		return enc.Encode(data)
-	case "yaml":
-		buf, err := yaml.Marshal(data)
-		if err != nil {
-			return err
-		}
-		if _, err := out.Write(buf); err != nil {
-			return err
-		}
-		_, err = out.Write([]byte("\n"))
-		return err
-	case "csv":
-		meta, primary := splitMetadata(data)
-		records, err := normalizeRecords(primary)
-		if err != nil {
-			return err
-		}
-		if err := writeMetadata(out, meta); err != nil {
-			return err
+	f, ok := format.Lookup(rt.OutputFormat)
+	if !ok {
+		return fmt.Errorf("unsupported output format %q", rt.OutputFormat)
+	}
+	if rt.OutFile == "" {
+		out := cmd.OutOrStdout()
+		highlighter, ok := f.(format.KeyHighlighter)
+		if !ok {
+			return f.Format(out, data)
 		}
-		return writeSeparated(out, records, ',')
-	case "tsv":
-		meta, primary := splitMetadata(data)
-		records, err := normalizeRecords(primary)
-		if err != nil {
-			return err
+		styler := style.For(out, rt.NoColor)
+		if !styler.Enabled() {
+			return f.Format(out, data)
 		}
-		if err := writeMetadata(out, meta); err != nil {
+		buf := &bytes.Buffer{}
+		if err := f.Format(buf, data); err != nil {
 			return err
 		}
-		return writeSeparated(out, records, '\t')
-	default:
-		return fmt.Errorf("unsupported output format %q", rt.OutputFormat)
+		_, err := out.Write(highlighter.HighlightKeys(buf.Bytes(), styler.Key))
+		return err
 	}
-}
 
-// MetadataProvider allows callers to supply metadata separate from rows.
-type MetadataProvider interface {
-	OutputMetadata() (interface{}, interface{})
-}
-
-func normalizeRecords(data interface{}) ([]map[string]any, error) {
-	raw, err := json.Marshal(data)
+	file, err := os.OpenFile(rt.OutFile, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o644)
 	if err != nil {
-		return nil, err
-	}
-	var anyData interface{}
-	if err := json.Unmarshal(raw, &anyData); err != nil {
-		return nil, err
-	}
-	return flattenAny(anyData)
-}
-
-func splitMetadata(data interface{}) (interface{}, interface{}) {
-	if provider, ok := data.(MetadataProvider); ok {
-		return provider.OutputMetadata()
+		return fmt.Errorf("open output file: %w", err)
 	}
-	switch v := data.(type) {
-	case map[string]any:
-		return extractMetadata(v)
-	default:
-		return nil, data
-	}
-}
-
-func extractMetadata(m map[string]any) (map[string]any, interface{}) {
-	if rows, ok := m["rows"]; ok {
-		meta := copyMap(m)
-		delete(meta, "rows")
-		if len(meta) == 0 {
-			meta = nil
-		}
-		return meta, rows
-	}
-	return nil, m
-}
-
-func writeMetadata(out io.Writer, meta interface{}) error {
-	if meta == nil {
-		return nil
-	}
-	if m, ok := meta.(map[string]any); ok && len(m) == 0 {
-		return nil
-	}
-	enc := json.NewEncoder(out)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(meta); err != nil {
+	if err := f.Format(file, data); err != nil {
+		file.Close()
 		return err
 	}
-	if _, err := out.Write([]byte("\n")); err != nil {
-		return err
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("write output file: %w", err)
 	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Output written to %s\n", rt.OutFile)
 	return nil
 }
 
-func flattenAny(value interface{}) ([]map[string]any, error) {
-	switch v := value.(type) {
-	case []interface{}:
-		res := make([]map[string]any, 0, len(v))
-		for _, item := range v {
-			m, err := toMap(item)
-			if err != nil {
-				return nil, err
-			}
-			res = append(res, m)
-		}
-		return res, nil
-	case map[string]interface{}:
-		if rows, ok := v["rows"].([]interface{}); ok {
-			base := copyMap(v)
-			delete(base, "rows")
-			res := make([]map[string]any, 0, len(rows)+1)
-			if len(base) > 0 {
-				res = append(res, base)
-			}
-			for _, row := range rows {
-				m, err := toMap(row)
-				if err != nil {
-					return nil, err
-				}
-				res = append(res, m)
-			}
-			return res, nil
-		}
-		m, err := toMap(v)
-		if err != nil {
-			return nil, err
-		}
-		return []map[string]any{m}, nil
-	default:
-		m, err := toMap(v)
-		if err != nil {
-			return nil, fmt.Errorf("data must be an object or array of objects for csv/tsv output")
-		}
-		return []map[string]any{m}, nil
-	}
-}
-
-func toMap(value interface{}) (map[string]any, error) {
-	if value == nil {
-		return map[string]any{}, nil
-	}
-	switch v := value.(type) {
-	case map[string]interface{}:
-		return v, nil
-	default:
-		return map[string]any{"value": v}, nil
-	}
-}
-
-func copyMap(src map[string]any) map[string]any {
-	dst := make(map[string]any, len(src))
-	for k, v := range src {
-		dst[k] = v
-	}
-	return dst
-}
-
-func writeSeparated(w io.Writer, records []map[string]any, sep rune) error {
-	if len(records) == 0 {
-		return nil
-	}
-	headers := collectHeaders(records)
-	writer := csv.NewWriter(w)
-	writer.Comma = sep
-	if err := writer.Write(headers); err != nil {
-		return err
-	}
-	row := make([]string, len(headers))
-	for _, rec := range records {
-		for i, h := range headers {
-			if val, ok := rec[h]; ok && val != nil {
-				row[i] = fmt.Sprintf("%v", val)
-			} else {
-				row[i] = ""
-			}
-		}
-		if err := writer.Write(row); err != nil {
-			return err
-		}
-	}
-	writer.Flush()
-	return writer.Error()
-}
+// MetadataProvider allows callers to supply metadata separate from rows.
+type MetadataProvider = format.MetadataProvider
 
-func collectHeaders(records []map[string]any) []string {
-	set := map[string]struct{}{}
-	for _, rec := range records {
-		for k := range rec {
-			set[k] = struct{}{}
-		}
-	}
-	headers := make([]string, 0, len(set))
-	for k := range set {
-		headers = append(headers, k)
+// Styler returns a Styler for w that honours the runtime --no-color setting.
+func Styler(cmd *cobra.Command, w io.Writer) style.Styler {
+	noColor := false
+	if rt, ok := runtime.FromContext(cmd.Context()); ok {
+		noColor = rt.NoColor
 	}
-	sort.Strings(headers)
-	return headers
+	return style.For(w, noColor)
 }
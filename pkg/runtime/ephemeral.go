@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+// EnvEphemeral requests an ephemeral connection without passing --ephemeral.
+const EnvEphemeral = "SNOWCTL_EPHEMERAL"
+
+// EphemeralFlags are the global flags that describe a connection for a single
+// invocation. Any non-empty field, or FromEnv, builds an in-memory context
+// instead of reading the config file.
+type EphemeralFlags struct {
+	Account    string
+	User       string
+	Role       string
+	Warehouse  string
+	AuthMethod string
+	// FromEnv requests an ephemeral context from environment variables alone.
+	FromEnv bool
+}
+
+func (f EphemeralFlags) requested() (bool, error) {
+	if f.FromEnv || f.Account != "" || f.User != "" || f.Role != "" || f.Warehouse != "" || f.AuthMethod != "" {
+		return true, nil
+	}
+	raw := strings.TrimSpace(os.Getenv(EnvEphemeral))
+	if raw == "" {
+		return false, nil
+	}
+	on, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, clierror.Newf(clierror.CategoryUsage, "%s=%q is not true or false", EnvEphemeral, raw)
+	}
+	return on, nil
+}
+
+// ephemeralContext builds a connection from flags, falling back to SNOWCTL_*
+// and then SNOWFLAKE_* variables for each setting. The secret is only read
+// from the environment so it never appears in process listings.
+func ephemeralContext(f EphemeralFlags) (*config.Context, error) {
+	ctx := &config.Context{
+		Account:    firstNonEmpty(f.Account, env("ACCOUNT")),
+		AccountURL: env("ACCOUNT_URL"),
+		User:       firstNonEmpty(f.User, env("USER")),
+		Role:       firstNonEmpty(f.Role, env("ROLE")),
+		Warehouse:  firstNonEmpty(f.Warehouse, env("WAREHOUSE")),
+		Database:   env("DATABASE"),
+		Schema:     env("SCHEMA"),
+		AuthMethod: strings.ToLower(firstNonEmpty(f.AuthMethod, os.Getenv("SNOWCTL_AUTH_METHOD"))),
+		Secret:     firstNonEmpty(os.Getenv("SNOWCTL_SECRET"), os.Getenv("SNOWFLAKE_PASSWORD")),
+	}
+	if ctx.AuthMethod == "" {
+		ctx.AuthMethod = "password"
+		if strings.EqualFold(os.Getenv("SNOWFLAKE_AUTHENTICATOR"), "PROGRAMMATIC_ACCESS_TOKEN") {
+			ctx.AuthMethod = "pat"
+		}
+	}
+
+	if err := config.ValidateAuthMethod(ctx.AuthMethod); err != nil {
+		return nil, clierror.Usage(err)
+	}
+	if ctx.Account == "" {
+		return nil, clierror.Configf("ephemeral connection needs an account: pass --account or set SNOWFLAKE_ACCOUNT")
+	}
+	if err := config.ValidateAccountIdentifier(ctx.Account); err != nil {
+		return nil, clierror.New(clierror.CategoryConfig, err)
+	}
+	if ctx.User == "" {
+		return nil, clierror.Configf("ephemeral connection needs a user: pass --user or set SNOWFLAKE_USER")
+	}
+	if ctx.Secret == "" {
+		return nil, clierror.Configf("ephemeral connection needs a secret: set SNOWCTL_SECRET or SNOWFLAKE_PASSWORD")
+	}
+	return ctx, nil
+}
+
+// env returns SNOWCTL_<key>, or SNOWFLAKE_<key> when that is unset.
+func env(key string) string {
+	return firstNonEmpty(os.Getenv("SNOWCTL_"+key), os.Getenv("SNOWFLAKE_"+key))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/format"
 )
 
 // Runtime captures process-wide configuration shared across commands.
@@ -14,28 +16,97 @@ type Runtime struct {
 	ActiveContext     *config.Context
 	ActiveContextName string
 	OutputFormat      string
+	// OutFile, when set, redirects structured output to a file instead of stdout.
+	OutFile string
+	// NoColor disables ANSI styling even on terminals.
+	NoColor bool
+	// SessionParams are one-off --session-param overrides layered over the
+	// params of whichever connection a command opens. An empty value unsets
+	// the param, so the Snowflake default applies for this run.
+	SessionParams map[string]string
+	// ConfigError is the load failure tolerated for commands annotated with
+	// AnnotationAllowConfigError; Config is then empty.
+	ConfigError error
+	// Ephemeral reports that the active connection was built from flags and
+	// environment variables; Config is then in-memory only and cannot be saved.
+	Ephemeral bool
+
+	// activeErr is why the active connection could not be resolved, such as
+	// a broken extends chain.
+	activeErr error
+}
+
+// AnnotationAllowConfigError marks commands, such as doctor, that must run
+// even when the configuration cannot be loaded.
+const AnnotationAllowConfigError = "snowctl/allow-config-error"
+
+// Options collects the global flags that shape a Runtime.
+type Options struct {
+	ContextOverride string
+	OutputFormat    string
+	OutFile         string
+	NoColor         bool
+	// ConfigPath overrides the config file location (--config); empty falls back to SNOWCTL_CONFIG or ~/.snowctl/config.
+	ConfigPath string
+	// SessionParams holds KEY=VALUE assignments from --session-param.
+	SessionParams []string
+	// AllowConfigError keeps going with an empty Config when loading fails,
+	// recording the failure in Runtime.ConfigError.
+	AllowConfigError bool
+	// Ephemeral describes a connection for this invocation only
+	// (--account, --user, ..., --ephemeral).
+	Ephemeral EphemeralFlags
 }
 
 type runtimeKey struct{}
 
 // NewRuntime loads CLI configuration, selects the active context, and validates output flags.
 func NewRuntime(contextOverride, output string) (*Runtime, error) {
-	cfg, err := config.Load()
+	return NewRuntimeWithOptions(Options{ContextOverride: contextOverride, OutputFormat: output})
+}
+
+// NewRuntimeWithOptions is NewRuntime with the full set of global options.
+func NewRuntimeWithOptions(opts Options) (*Runtime, error) {
+	if err := config.SetPath(opts.ConfigPath); err != nil {
+		return nil, clierror.New(clierror.CategoryConfig, err)
+	}
+	ephemeral, err := opts.Ephemeral.requested()
 	if err != nil {
 		return nil, err
 	}
 
-	normalizedOutput := strings.ToLower(output)
-	if normalizedOutput == "" {
-		normalizedOutput = "json"
+	var cfg *config.Config
+	var loadErr error
+	if ephemeral {
+		if opts.ContextOverride != "" {
+			return nil, clierror.Newf(clierror.CategoryUsage, "--connection cannot be combined with an ephemeral connection (--account, --user, --role, --warehouse, --auth-method, --ephemeral)")
+		}
+		ctx, err := ephemeralContext(opts.Ephemeral)
+		if err != nil {
+			return nil, err
+		}
+		cfg = config.NewEphemeral(ctx)
+	} else if cfg, loadErr = config.Load(); loadErr != nil {
+		if !opts.AllowConfigError {
+			return nil, clierror.New(clierror.CategoryConfig, loadErr)
+		}
+		cfg = config.DefaultConfig()
 	}
-	switch normalizedOutput {
-	case "json", "yaml", "csv", "tsv":
-	default:
-		return nil, fmt.Errorf("unsupported output format %q (supported: json, yaml, csv, tsv)", output)
+
+	normalizedOutput, err := format.Normalize(opts.OutputFormat)
+	if err != nil {
+		return nil, clierror.Usage(err)
+	}
+	if f, _ := format.Lookup(normalizedOutput); format.IsBinary(f) && strings.TrimSpace(opts.OutFile) == "" {
+		return nil, clierror.Newf(clierror.CategoryUsage, "output format %q writes a binary file; pass --out-file PATH", normalizedOutput)
 	}
 
-	ctxName := contextOverride
+	sessionParams, err := parseSessionParams(opts.SessionParams)
+	if err != nil {
+		return nil, clierror.Usage(err)
+	}
+
+	ctxName := opts.ContextOverride
 	if ctxName == "" {
 		ctxName = cfg.CurrentContext
 	}
@@ -43,11 +114,16 @@ func NewRuntime(contextOverride, output string) (*Runtime, error) {
 		ctxName = cfg.DefaultContext
 	}
 
+	// The active context carries effective values, with extends resolved.
 	var active *config.Context
+	var activeErr error
 	if ctxName != "" {
-		if c, ok := cfg.Contexts[ctxName]; ok {
-			c.Name = ctxName
-			active = c
+		if _, ok := cfg.GetContext(ctxName); ok {
+			c, _, err := cfg.ResolveContext(ctxName)
+			if err == nil {
+				active = withSessionParams(c, sessionParams)
+			}
+			activeErr = err
 		}
 	}
 
@@ -56,9 +132,74 @@ func NewRuntime(contextOverride, output string) (*Runtime, error) {
 		ActiveContext:     active,
 		ActiveContextName: ctxName,
 		OutputFormat:      normalizedOutput,
+		OutFile:           strings.TrimSpace(opts.OutFile),
+		NoColor:           opts.NoColor,
+		SessionParams:     sessionParams,
+		ConfigError:       loadErr,
+		Ephemeral:         ephemeral,
+		activeErr:         activeErr,
 	}, nil
 }
 
+// ResolveContext returns the effective settings of a stored connection, with
+// extends resolved and --session-param overrides applied.
+func (rt *Runtime) ResolveContext(name string) (*config.Context, error) {
+	ctx, _, err := rt.Config.ResolveContext(name)
+	if err != nil {
+		return nil, err
+	}
+	return withSessionParams(ctx, rt.SessionParams), nil
+}
+
+// SelectContexts returns the sorted names of stored connections whose labels
+// match selector, e.g. "env=prod,team in (finance,ops)". An empty selector
+// matches every connection, including ones whose extends chain is broken.
+func (rt *Runtime) SelectContexts(selector string) ([]string, error) {
+	sel, err := config.ParseSelector(selector)
+	if err != nil {
+		return nil, clierror.Usage(err)
+	}
+	return rt.Config.SelectContexts(sel), nil
+}
+
+// parseSessionParams parses --session-param assignments. Unlike stored params,
+// KEY= is kept as an empty override rather than dropped: it unsets a param
+// inherited from the connection or its extends parent.
+func parseSessionParams(assignments []string) (map[string]string, error) {
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	res := make(map[string]string, len(assignments))
+	for _, assignment := range assignments {
+		key, value, err := config.ParseParam(assignment)
+		if err != nil {
+			return nil, err
+		}
+		res[key] = value
+	}
+	return res, nil
+}
+
+func withSessionParams(ctx *config.Context, overrides map[string]string) *config.Context {
+	if len(overrides) == 0 {
+		return ctx
+	}
+	if ctx.Params == nil {
+		ctx.Params = make(map[string]string, len(overrides))
+	}
+	for k, v := range overrides {
+		if v == "" {
+			delete(ctx.Params, k)
+			continue
+		}
+		ctx.Params[k] = v
+	}
+	if len(ctx.Params) == 0 {
+		ctx.Params = nil
+	}
+	return ctx
+}
+
 // WithRuntime attaches runtime metadata to a context.
 func WithRuntime(ctx context.Context, rt *Runtime) context.Context {
 	return context.WithValue(ctx, runtimeKey{}, rt)
@@ -88,8 +229,11 @@ func RequireActiveContext(ctx context.Context) (*config.Context, error) {
 	if err != nil {
 		return nil, err
 	}
+	if rt.activeErr != nil {
+		return nil, clierror.New(clierror.CategoryConfig, rt.activeErr)
+	}
 	if rt.ActiveContext == nil {
-		return nil, fmt.Errorf("no active connection configured. Configure one via 'snowctl connection set' and 'snowctl connection use'")
+		return nil, clierror.Configf("no active connection configured. Configure one via 'snowctl connection set' and 'snowctl connection use'")
 	}
 	return rt.ActiveContext, nil
 }
@@ -2,8 +2,10 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
@@ -55,3 +57,157 @@ func TestRequireActiveContext(t *testing.T) {
 		t.Fatalf("expected context 'one', got %s", ctx.Name)
 	}
 }
+
+func TestRequireActiveContextReportsBrokenExtends(t *testing.T) {
+	setupConfig(t)
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	cfg.Contexts["one"].Extends = "missing"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	rt, err := NewRuntime("", "json")
+	if err != nil {
+		t.Fatalf("NewRuntime should tolerate a broken chain: %v", err)
+	}
+	if _, err := RequireActiveContext(WithRuntime(context.Background(), rt)); err == nil || !strings.Contains(err.Error(), `extends unknown connection "missing"`) {
+		t.Fatalf("expected broken chain error, got %v", err)
+	}
+}
+
+func TestNewRuntimeBinaryOutputRequiresFile(t *testing.T) {
+	setupConfig(t)
+	if _, err := NewRuntime("", "xlsx"); err == nil {
+		t.Fatalf("expected error for xlsx without --out-file")
+	}
+	rt, err := NewRuntimeWithOptions(Options{OutputFormat: "xlsx", OutFile: "results.xlsx"})
+	if err != nil {
+		t.Fatalf("NewRuntimeWithOptions: %v", err)
+	}
+	if rt.OutFile != "results.xlsx" {
+		t.Fatalf("expected out file to be recorded, got %q", rt.OutFile)
+	}
+}
+
+func TestSessionParamsOverrideConnectionParams(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	cfg := config.DefaultConfig()
+	cfg.SetContext("one", &config.Context{Account: "acct", Params: map[string]string{"QUERY_TAG": "etl", "TIMEZONE": "UTC"}})
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	rt, err := NewRuntimeWithOptions(Options{OutputFormat: "json", SessionParams: []string{"query_tag=adhoc"}})
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	if got := rt.ActiveContext.Params; got["QUERY_TAG"] != "adhoc" || got["TIMEZONE"] != "UTC" {
+		t.Fatalf("unexpected effective params: %v", got)
+	}
+	if rt.Config.Contexts["one"].Params["QUERY_TAG"] != "etl" {
+		t.Fatalf("override must not leak into stored config")
+	}
+
+	// KEY= unsets a param for this run only, including inherited ones.
+	cfg.SetContext("child", &config.Context{Extends: "one"})
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	rt, err = NewRuntimeWithOptions(Options{ContextOverride: "child", OutputFormat: "json", SessionParams: []string{"timezone=", "query_tag="}})
+	if err != nil {
+		t.Fatalf("NewRuntime: %v", err)
+	}
+	if got := rt.ActiveContext.Params; got != nil {
+		t.Fatalf("expected inherited params unset, got %v", got)
+	}
+	if v, ok := rt.SessionParams["TIMEZONE"]; !ok || v != "" {
+		t.Fatalf("expected an empty override to be kept, got %v", rt.SessionParams)
+	}
+	other, err := rt.ResolveContext("one")
+	if err != nil || other.Params["QUERY_TAG"] != "" || len(other.Params) != 0 {
+		t.Fatalf("expected the override on every opened connection, got %v (%v)", other, err)
+	}
+	if rt.Config.Contexts["one"].Params["TIMEZONE"] != "UTC" {
+		t.Fatalf("unset must not leak into stored config")
+	}
+
+	if _, err := NewRuntimeWithOptions(Options{OutputFormat: "json", SessionParams: []string{"no-equals"}}); err == nil {
+		t.Fatalf("expected error for malformed --session-param")
+	}
+}
+
+func TestEphemeralContextFromFlagsAndEnv(t *testing.T) {
+	path := setupConfig(t)
+	t.Setenv("SNOWFLAKE_USER", "env_user")
+	t.Setenv("SNOWFLAKE_WAREHOUSE", "ENV_WH")
+	t.Setenv("SNOWCTL_WAREHOUSE", "CI_WH")
+	t.Setenv("SNOWFLAKE_PASSWORD", "s3cret")
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+
+	rt, err := NewRuntimeWithOptions(Options{OutputFormat: "json", Ephemeral: EphemeralFlags{Account: "myorg-ci", Role: "LOADER"}})
+	if err != nil {
+		t.Fatalf("NewRuntimeWithOptions: %v", err)
+	}
+	ctx := rt.ActiveContext
+	if !rt.Ephemeral || rt.ActiveContextName != config.EphemeralContextName {
+		t.Fatalf("expected ephemeral runtime, got %+v", rt)
+	}
+	if ctx.Account != "myorg-ci" || ctx.User != "env_user" || ctx.Role != "LOADER" || ctx.Warehouse != "CI_WH" || ctx.Secret != "s3cret" || ctx.AuthMethod != "password" {
+		t.Fatalf("unexpected ephemeral context: %+v", ctx)
+	}
+	if _, ok := rt.Config.GetContext("one"); ok {
+		t.Fatalf("ephemeral runtime must not read stored connections")
+	}
+
+	if err := config.Save(rt.Config); !errors.Is(err, config.ErrEphemeral) {
+		t.Fatalf("expected Save to refuse ephemeral config, got %v", err)
+	}
+	after, _ := os.ReadFile(path)
+	if string(before) != string(after) {
+		t.Fatalf("config file changed by ephemeral runtime")
+	}
+}
+
+func TestEphemeralContextFromEnvOnly(t *testing.T) {
+	setupConfig(t)
+	t.Setenv(EnvEphemeral, "true")
+	t.Setenv("SNOWFLAKE_ACCOUNT", "xy12345.us-east-1")
+	t.Setenv("SNOWFLAKE_USER", "svc")
+	t.Setenv("SNOWFLAKE_AUTHENTICATOR", "PROGRAMMATIC_ACCESS_TOKEN")
+	t.Setenv("SNOWCTL_SECRET", "token")
+
+	rt, err := NewRuntimeWithOptions(Options{OutputFormat: "json"})
+	if err != nil {
+		t.Fatalf("NewRuntimeWithOptions: %v", err)
+	}
+	if rt.ActiveContext.AuthMethod != "pat" || rt.ActiveContext.Secret != "token" {
+		t.Fatalf("unexpected ephemeral context: %+v", rt.ActiveContext)
+	}
+
+	if _, err := NewRuntimeWithOptions(Options{OutputFormat: "json", ContextOverride: "one"}); err == nil {
+		t.Fatalf("expected --connection to conflict with an ephemeral connection")
+	}
+}
+
+func TestEphemeralContextRequiresCredentials(t *testing.T) {
+	setupConfig(t)
+	for _, key := range []string{"SNOWCTL_USER", "SNOWFLAKE_USER", "SNOWCTL_SECRET", "SNOWFLAKE_PASSWORD"} {
+		t.Setenv(key, "")
+	}
+	if _, err := NewRuntimeWithOptions(Options{Ephemeral: EphemeralFlags{Account: "myorg-ci"}}); err == nil || !strings.Contains(err.Error(), "--user") {
+		t.Fatalf("expected missing user error, got %v", err)
+	}
+	if _, err := NewRuntimeWithOptions(Options{Ephemeral: EphemeralFlags{Account: "myorg-ci", User: "svc"}}); err == nil || !strings.Contains(err.Error(), "SNOWFLAKE_PASSWORD") {
+		t.Fatalf("expected missing secret error, got %v", err)
+	}
+	if _, err := NewRuntimeWithOptions(Options{Ephemeral: EphemeralFlags{Account: "https://x.snowflakecomputing.com", User: "svc"}}); err == nil {
+		t.Fatalf("expected invalid account error")
+	}
+}
@@ -0,0 +1,320 @@
+package snowflake
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/snowflakedb/gosnowflake"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+var (
+	lookupHostFunc = net.DefaultResolver.LookupHost
+	dialFunc       = (&net.Dialer{}).DialContext
+	ocspClient     = http.DefaultClient
+)
+
+// errRoleRejected is the Snowflake error number for a login whose role does
+// not exist or is not granted to the user.
+const errRoleRejected = 390189
+
+// Stage outcomes reported by Diagnose.
+const (
+	StageOK      = "ok"
+	StageFailed  = "failed"
+	StageSkipped = "skipped"
+)
+
+// Stage is the outcome of one diagnostic step.
+type Stage struct {
+	Name       string `json:"stage" yaml:"stage"`
+	Status     string `json:"status" yaml:"status"`
+	DurationMS int64  `json:"durationMs" yaml:"durationMs"`
+	Detail     string `json:"detail,omitempty" yaml:"detail,omitempty"`
+	Category   string `json:"category,omitempty" yaml:"category,omitempty"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Diagnosis is the staged report of a connection attempt.
+type Diagnosis struct {
+	Host          string  `json:"host" yaml:"host"`
+	Port          int     `json:"port" yaml:"port"`
+	DriverVersion string  `json:"driverVersion" yaml:"driverVersion"`
+	ServerVersion string  `json:"serverVersion,omitempty" yaml:"serverVersion,omitempty"`
+	ServerTime    string  `json:"serverTime,omitempty" yaml:"serverTime,omitempty"`
+	Stages        []Stage `json:"stages" yaml:"stages"`
+}
+
+// Diagnose connects to Snowflake one step at a time: resolving the host,
+// opening a TCP connection and TLS handshake, checking the certificate's
+// revocation status over OCSP, logging in, and then checking that the
+// configured role, warehouse, database, and schema are the ones the session
+// actually uses. Steps after the first failure are skipped. The returned
+// error is that failure, categorized; the diagnosis is returned either way.
+func Diagnose(ctx context.Context, info *config.Context) (*Diagnosis, error) {
+	if info == nil {
+		return nil, fmt.Errorf("connection info is required")
+	}
+	d := &Diagnosis{DriverVersion: gosnowflake.SnowflakeGoDriverVersion}
+	cfg, err := driverConfig(info)
+	if err != nil {
+		return d, clierror.New(clierror.CategoryConfig, err)
+	}
+	d.Host, d.Port = endpoint(cfg)
+
+	ctx, cancel := context.WithTimeout(ctx, sessionTimeout(15*time.Second, info))
+	defer cancel()
+
+	var failure error
+	run := func(name string, fn func() (string, error)) {
+		if failure != nil {
+			d.Stages = append(d.Stages, Stage{Name: name, Status: StageSkipped})
+			return
+		}
+		start := time.Now()
+		detail, err := fn()
+		stage := Stage{Name: name, Status: StageOK, DurationMS: time.Since(start).Milliseconds(), Detail: detail}
+		if err != nil {
+			classified := clierror.Classify(err)
+			stage.Status = StageFailed
+			stage.Category = string(classified.Category)
+			stage.Error = err.Error()
+			failure = clierror.New(classified.Category, fmt.Errorf("%s: %w", name, err))
+		}
+		d.Stages = append(d.Stages, stage)
+	}
+
+	// Behind a proxy the driver never talks to Snowflake directly, so the
+	// network checks target the proxy and TLS is left to the login.
+	host, port, useTLS := d.Host, d.Port, cfg.Protocol != "http"
+	if cfg.ProxyHost != "" {
+		host, port, useTLS = cfg.ProxyHost, cfg.ProxyPort, false
+	}
+
+	var addrs []string
+	run("resolve", func() (string, error) {
+		var err error
+		addrs, err = lookupHostFunc(ctx, host)
+		if err != nil {
+			return "", clierror.New(clierror.CategoryNetwork, err)
+		}
+		return fmt.Sprintf("%s -> %s", host, strings.Join(addrs, ", ")), nil
+	})
+	var tlsState *tls.ConnectionState
+	run("connect", func() (string, error) {
+		var detail string
+		var err error
+		detail, tlsState, err = connect(ctx, host, port, useTLS, cfg.ProxyHost != "")
+		return detail, err
+	})
+	run("ocsp", func() (string, error) {
+		switch {
+		case cfg.ProxyHost != "":
+			return "checked by the driver at login (proxy)", nil
+		case tlsState == nil:
+			return "not applicable without TLS", nil
+		}
+		return checkOCSP(ctx, tlsState, info.OCSPFailOpen == nil || *info.OCSPFailOpen)
+	})
+
+	var db *sql.DB
+	var roleErr error
+	run("authenticate", func() (string, error) {
+		dsn, err := dsnFunc(cfg)
+		if err != nil {
+			return "", clierror.New(clierror.CategoryConfig, fmt.Errorf("build DSN: %w", err))
+		}
+		db, err = openFunc("snowflake", dsn)
+		if err != nil {
+			return "", fmt.Errorf("open connection: %w", err)
+		}
+		if err := db.PingContext(ctx); err != nil {
+			var sfErr *gosnowflake.SnowflakeError
+			if errors.As(err, &sfErr) && sfErr.Number == errRoleRejected {
+				// The credentials were accepted; the role stage reports the rest.
+				roleErr = err
+				return fmt.Sprintf("%s as %s; role rejected at login", info.AuthMethod, info.User), nil
+			}
+			return "", err
+		}
+		return fmt.Sprintf("%s as %s", info.AuthMethod, info.User), nil
+	})
+	if db != nil {
+		defer db.Close()
+	}
+
+	var current struct{ version, role, warehouse, database, schema, now sql.NullString }
+	if roleErr != nil {
+		d.Stages = append(d.Stages, Stage{Name: "session", Status: StageSkipped, Detail: "no session without a usable role"})
+	} else {
+		run("session", func() (string, error) {
+			err := db.QueryRowContext(ctx, "select current_version(), current_role(), current_warehouse(), current_database(), current_schema(), current_timestamp()").
+				Scan(&current.version, &current.role, &current.warehouse, &current.database, &current.schema, &current.now)
+			if err != nil {
+				return "", err
+			}
+			d.ServerVersion, d.ServerTime = current.version.String, current.now.String
+			return "Snowflake " + current.version.String, nil
+		})
+	}
+	run("role", func() (string, error) {
+		if roleErr != nil {
+			return "", roleErr
+		}
+		return checkCurrent("role", info.Role, current.role)
+	})
+	run("warehouse", func() (string, error) { return checkCurrent("warehouse", info.Warehouse, current.warehouse) })
+	run("database", func() (string, error) { return checkCurrent("database", info.Database, current.database) })
+	run("schema", func() (string, error) { return checkCurrent("schema", info.Schema, current.schema) })
+
+	return d, failure
+}
+
+// endpoint returns the host and port the driver connects to, filling the
+// defaults gosnowflake applies when they are not configured.
+func endpoint(cfg *gosnowflake.Config) (string, int) {
+	host, port := cfg.Host, cfg.Port
+	if host == "" {
+		host = strings.ToLower(cfg.Account) + ".snowflakecomputing.com"
+	}
+	if port == 0 {
+		port = 443
+		if cfg.Protocol == "http" {
+			port = 80
+		}
+	}
+	return host, port
+}
+
+// connect opens a TCP connection to host and, for https, completes a TLS
+// handshake with certificate verification, returning the handshake state.
+func connect(ctx context.Context, host string, port int, useTLS, viaProxy bool) (string, *tls.ConnectionState, error) {
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	conn, err := dialFunc(ctx, "tcp", addr)
+	if err != nil {
+		return "", nil, clierror.New(clierror.CategoryNetwork, err)
+	}
+	defer conn.Close()
+	if viaProxy {
+		return fmt.Sprintf("proxy %s reachable; TLS is checked at login", addr), nil, nil
+	}
+	if !useTLS {
+		return fmt.Sprintf("TCP %s (no TLS)", conn.RemoteAddr()), nil, nil
+	}
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return "", nil, clierror.New(clierror.CategoryNetwork, fmt.Errorf("TLS handshake: %w", err))
+	}
+	state := tlsConn.ConnectionState()
+	return fmt.Sprintf("%s to %s", tls.VersionName(state.Version), conn.RemoteAddr()), &state, nil
+}
+
+// checkOCSP checks the revocation status of the server certificate, using a
+// stapled response when the server sent one and the certificate's OCSP
+// responder otherwise. A revoked certificate always fails. When the status
+// cannot be determined the check passes under fail-open and fails under
+// fail-closed, matching what the driver does at login.
+func checkOCSP(ctx context.Context, state *tls.ConnectionState, failOpen bool) (string, error) {
+	if len(state.PeerCertificates) < 2 {
+		return "no issuer certificate to check against", nil
+	}
+	leaf, issuer := state.PeerCertificates[0], state.PeerCertificates[1]
+
+	source := "stapled response"
+	raw := state.OCSPResponse
+	if len(raw) == 0 {
+		if len(leaf.OCSPServer) == 0 {
+			return "certificate names no OCSP responder", nil
+		}
+		source = leaf.OCSPServer[0]
+		var err error
+		if raw, err = queryOCSP(ctx, source, leaf, issuer); err != nil {
+			return undetermined(failOpen, fmt.Errorf("OCSP responder %s: %w", source, err))
+		}
+	}
+	resp, err := ocsp.ParseResponseForCert(raw, leaf, issuer)
+	if err != nil {
+		return undetermined(failOpen, fmt.Errorf("OCSP response from %s: %w", source, err))
+	}
+	switch resp.Status {
+	case ocsp.Good:
+		return "certificate good (" + source + ")", nil
+	case ocsp.Revoked:
+		return "", clierror.Newf(clierror.CategoryNetwork, "certificate %s was revoked at %s (%s)", leaf.Subject.CommonName, resp.RevokedAt.Format(time.RFC3339), source)
+	default:
+		return undetermined(failOpen, fmt.Errorf("OCSP status unknown (%s)", source))
+	}
+}
+
+// undetermined reports an OCSP check that could not reach a verdict.
+func undetermined(failOpen bool, err error) (string, error) {
+	if failOpen {
+		return fmt.Sprintf("%v; allowed by ocspFailOpen", err), nil
+	}
+	return "", clierror.New(clierror.CategoryNetwork, fmt.Errorf("%w; refused because ocspFailOpen is false", err))
+}
+
+func queryOCSP(ctx context.Context, responder string, leaf, issuer *x509.Certificate) ([]byte, error) {
+	body, err := ocsp.CreateRequest(leaf, issuer, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responder, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/ocsp-request")
+	resp, err := ocspClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+// checkCurrent compares a configured object with the one the session uses.
+// Snowflake drops a role, warehouse, database, or schema from the session
+// when it does not exist or the user cannot use it, so a mismatch means the
+// configured value is unusable.
+func checkCurrent(kind, configured string, current sql.NullString) (string, error) {
+	if configured == "" {
+		if !current.Valid || current.String == "" {
+			return "none configured", nil
+		}
+		return "default " + current.String, nil
+	}
+	if current.Valid && SameIdentifier(configured, current.String) {
+		return current.String, nil
+	}
+	using := "none"
+	if current.Valid && current.String != "" {
+		using = current.String
+	}
+	return "", clierror.Newf(clierror.CategoryNotFound, "%s %s is not in use (session has %s): it does not exist or is not granted to this user", kind, configured, using)
+}
+
+// SameIdentifier compares identifiers the way Snowflake resolves them:
+// unquoted names are case-insensitive, quoted names are exact.
+func SameIdentifier(configured, current string) bool {
+	if unquoted, ok := strings.CutPrefix(configured, `"`); ok {
+		return strings.TrimSuffix(unquoted, `"`) == current
+	}
+	return strings.EqualFold(configured, current)
+}
@@ -0,0 +1,206 @@
+package snowflake
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/snowflakedb/gosnowflake"
+	"golang.org/x/crypto/ocsp"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+func stageByName(t *testing.T, d *Diagnosis, name string) Stage {
+	t.Helper()
+	for _, s := range d.Stages {
+		if s.Name == name {
+			return s
+		}
+	}
+	t.Fatalf("stage %s missing from %+v", name, d.Stages)
+	return Stage{}
+}
+
+func TestDiagnoseReportsEachStage(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	mock, cleanup := withMockDB(t)
+	defer cleanup()
+
+	mock.ExpectPing()
+	mock.ExpectQuery("select current_version\\(\\)").WillReturnRows(
+		sqlmock.NewRows([]string{"V", "R", "W", "D", "S", "T"}).AddRow("9.1.0", "ANALYST", nil, "SALES", "PUBLIC", "2025-01-01T00:00:00Z"))
+
+	info := &config.Context{
+		Name: "dev", Account: "acct", User: "me", AuthMethod: "password", Secret: "secret",
+		Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, Protocol: "http",
+		Role: "analyst", Warehouse: "MISSING_WH",
+	}
+	d, err := Diagnose(context.Background(), info)
+	if err == nil || clierror.Classify(err).Category != clierror.CategoryNotFound || !strings.Contains(err.Error(), "warehouse") {
+		t.Fatalf("expected not_found warehouse failure, got %v", err)
+	}
+	if d.ServerVersion != "9.1.0" || d.DriverVersion == "" || d.ServerTime == "" || d.Host != "127.0.0.1" {
+		t.Fatalf("unexpected diagnosis: %+v", d)
+	}
+	want := map[string]string{
+		"resolve": StageOK, "connect": StageOK, "ocsp": StageOK, "authenticate": StageOK, "session": StageOK,
+		"role": StageOK, "warehouse": StageFailed, "database": StageSkipped, "schema": StageSkipped,
+	}
+	if len(d.Stages) != len(want) {
+		t.Fatalf("unexpected stages: %+v", d.Stages)
+	}
+	for name, status := range want {
+		if got := stageByName(t, d, name); got.Status != status {
+			t.Fatalf("stage %s: expected %s, got %+v", name, status, got)
+		}
+	}
+	if wh := stageByName(t, d, "warehouse"); wh.Category != "not_found" || !strings.Contains(wh.Error, "session has none") {
+		t.Fatalf("unexpected warehouse stage: %+v", wh)
+	}
+	if role := stageByName(t, d, "role"); role.Detail != "ANALYST" {
+		t.Fatalf("expected role matched case-insensitively, got %+v", role)
+	}
+}
+
+func TestDiagnoseStopsAtTLSFailure(t *testing.T) {
+	srv := httptest.NewTLSServer(nil)
+	defer srv.Close()
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+
+	d, err := Diagnose(context.Background(), &config.Context{
+		Account: "acct", User: "me", AuthMethod: "password", Secret: "secret",
+		Host: "127.0.0.1", Port: addr.Port,
+	})
+	if err == nil || clierror.Classify(err).Category != clierror.CategoryNetwork {
+		t.Fatalf("expected network failure, got %v", err)
+	}
+	if c := stageByName(t, d, "connect"); c.Status != StageFailed || !strings.Contains(c.Error, "TLS handshake") {
+		t.Fatalf("unexpected connect stage: %+v", c)
+	}
+	if a := stageByName(t, d, "authenticate"); a.Status != StageSkipped {
+		t.Fatalf("expected authenticate skipped, got %+v", a)
+	}
+}
+
+func TestDiagnoseReportsRejectedRoleUnderRole(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("sqlmock: %v", err)
+	}
+	origOpen, origDSN := openFunc, dsnFunc
+	openFunc = func(driverName, dsn string) (*sql.DB, error) { return db, nil }
+	dsnFunc = func(cfg *gosnowflake.Config) (string, error) { return "dsn", nil }
+	defer func() { openFunc, dsnFunc = origOpen, origDSN }()
+	mock.ExpectPing().WillReturnError(&gosnowflake.SnowflakeError{Number: 390189, Message: "Role 'NOPE' specified in the connect string does not exist or not authorized."})
+
+	d, err := Diagnose(context.Background(), &config.Context{
+		Account: "acct", User: "me", AuthMethod: "password", Secret: "secret", Role: "NOPE",
+		Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, Protocol: "http",
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "role: ") {
+		t.Fatalf("expected role failure, got %v", err)
+	}
+	if a := stageByName(t, d, "authenticate"); a.Status != StageOK {
+		t.Fatalf("expected credentials accepted, got %+v", a)
+	}
+	if s := stageByName(t, d, "session"); s.Status != StageSkipped {
+		t.Fatalf("expected session skipped, got %+v", s)
+	}
+	if r := stageByName(t, d, "role"); r.Status != StageFailed || !strings.Contains(r.Error, "NOPE") {
+		t.Fatalf("expected role stage to fail, got %+v", r)
+	}
+	if w := stageByName(t, d, "warehouse"); w.Status != StageSkipped {
+		t.Fatalf("expected warehouse skipped, got %+v", w)
+	}
+}
+
+// ocspFixture issues a CA and a leaf certificate whose OCSP responder is an
+// httptest server answering with status.
+func ocspFixture(t *testing.T, status int) (*tls.ConnectionState, *httptest.Server) {
+	t.Helper()
+	caKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	caTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1), Subject: pkix.Name{CommonName: "test CA"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour),
+		IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	caDER, _ := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	ca, _ := x509.ParseCertificate(caDER)
+
+	var leaf *x509.Certificate
+	responder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, err := ocsp.CreateResponse(ca, ca, ocsp.Response{
+			Status: status, SerialNumber: leaf.SerialNumber, RevokedAt: time.Now().Add(-time.Minute),
+			ThisUpdate: time.Now().Add(-time.Minute), NextUpdate: time.Now().Add(time.Hour),
+		}, caKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/ocsp-response")
+		w.Write(resp)
+	}))
+	t.Cleanup(responder.Close)
+
+	leafKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	leafTmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2), Subject: pkix.Name{CommonName: "acct.snowflakecomputing.com"},
+		NotBefore: time.Now().Add(-time.Hour), NotAfter: time.Now().Add(time.Hour),
+		OCSPServer: []string{responder.URL},
+	}
+	leafDER, _ := x509.CreateCertificate(rand.Reader, leafTmpl, ca, &leafKey.PublicKey, caKey)
+	leaf, _ = x509.ParseCertificate(leafDER)
+	return &tls.ConnectionState{PeerCertificates: []*x509.Certificate{leaf, ca}}, responder
+}
+
+func TestCheckOCSP(t *testing.T) {
+	state, _ := ocspFixture(t, ocsp.Good)
+	if detail, err := checkOCSP(context.Background(), state, false); err != nil || !strings.Contains(detail, "good") {
+		t.Fatalf("expected good status, got %q (%v)", detail, err)
+	}
+
+	state, _ = ocspFixture(t, ocsp.Revoked)
+	if _, err := checkOCSP(context.Background(), state, true); err == nil || !strings.Contains(err.Error(), "revoked") {
+		t.Fatalf("expected revoked certificate to fail even when fail-open, got %v", err)
+	}
+
+	state, responder := ocspFixture(t, ocsp.Good)
+	responder.Close()
+	if detail, err := checkOCSP(context.Background(), state, true); err != nil || !strings.Contains(detail, "allowed by ocspFailOpen") {
+		t.Fatalf("expected unreachable responder to pass when fail-open, got %q (%v)", detail, err)
+	}
+	_, err := checkOCSP(context.Background(), state, false)
+	if err == nil || clierror.Classify(err).Category != clierror.CategoryNetwork || !strings.Contains(err.Error(), "ocspFailOpen is false") {
+		t.Fatalf("expected unreachable responder to fail when fail-closed, got %v", err)
+	}
+}
+
+func TestSameIdentifier(t *testing.T) {
+	if !SameIdentifier("analyst", "ANALYST") || SameIdentifier(`"analyst"`, "ANALYST") || !SameIdentifier(`"Mixed"`, "Mixed") {
+		t.Fatalf("identifier comparison does not follow Snowflake quoting rules")
+	}
+}
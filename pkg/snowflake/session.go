@@ -0,0 +1,75 @@
+package snowflake
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+)
+
+// Session is one login that runs statements one after another, so commands
+// that issue several statements authenticate (and prompt for MFA) only once.
+type Session struct {
+	info *config.Context
+	db   *sql.DB
+	conn *sql.Conn
+}
+
+// OpenSession logs in with info. The session stays open until Close.
+func OpenSession(ctx context.Context, info *config.Context) (*Session, error) {
+	if info == nil {
+		return nil, fmt.Errorf("connection info is required")
+	}
+	dsn, err := buildDSN(info)
+	if err != nil {
+		return nil, err
+	}
+	db, err := openFunc("snowflake", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open connection: %w", err)
+	}
+
+	loginCtx, cancel := context.WithTimeout(ctx, sessionTimeout(15*time.Second, info))
+	defer cancel()
+	conn, err := db.Conn(loginCtx)
+	if err == nil {
+		err = conn.PingContext(loginCtx)
+	}
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		db.Close()
+		return nil, fmt.Errorf("ping snowflake: %w", err)
+	}
+	return &Session{info: info, db: db, conn: conn}, nil
+}
+
+// RunQuery executes stmt in the session and returns rows as maps.
+func (s *Session) RunQuery(ctx context.Context, stmt string) ([]map[string]any, error) {
+	res, err := s.Query(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return res.Rows, nil
+}
+
+// Query executes stmt in the session, applying the same read-only check as
+// the package-level Query.
+func (s *Session) Query(ctx context.Context, stmt string) (*QueryResult, error) {
+	if err := CheckReadOnly(s.info, stmt); err != nil {
+		return nil, err
+	}
+	return query(ctx, s.conn, s.info, stmt)
+}
+
+// Close logs out of the session.
+func (s *Session) Close() error {
+	connErr := s.conn.Close()
+	if err := s.db.Close(); err != nil {
+		return err
+	}
+	return connErr
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/snowflakedb/gosnowflake"
 	_ "github.com/snowflakedb/gosnowflake"
 
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/clierror"
 	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/config"
+	"github.com/Snowflake-Labs/Snowflake.SnowCTL/pkg/sqlstmt"
 )
 
 var (
@@ -18,31 +21,99 @@ var (
 	openFunc = sql.Open
 )
 
-// TestConnection attempts to connect to Snowflake using the provided connection info.
-// It returns the server's CURRENT_TIMESTAMP upon success.
-func TestConnection(ctx context.Context, info *config.Context) (string, error) {
-	if info == nil {
-		return "", fmt.Errorf("connection info is required")
+// driverConfig maps a connection profile onto the gosnowflake configuration
+// shared by every session snowctl opens.
+func driverConfig(info *config.Context) (*gosnowflake.Config, error) {
+	secret := strings.TrimSpace(info.Secret)
+	if secret == "" {
+		return nil, fmt.Errorf("connection %q has no stored credential", info.Name)
 	}
 	cfg := &gosnowflake.Config{
 		Account:   info.Account,
 		User:      info.User,
+		Password:  secret,
 		Role:      info.Role,
 		Warehouse: info.Warehouse,
 		Database:  info.Database,
 		Schema:    info.Schema,
 	}
+	if len(info.Params) > 0 {
+		cfg.Params = make(map[string]*string, len(info.Params))
+		for key, value := range info.Params {
+			cfg.Params[key] = &value
+		}
+	}
+	// Snowflake has no read-only session mode, so readOnly connections get no
+	// driver setting: CheckReadOnly in Query and Session.Query enforces them.
+	if err := applyNetwork(cfg, info); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
 
-	secret := strings.TrimSpace(info.Secret)
-	if secret == "" {
-		return "", fmt.Errorf("connection %q has no stored credential", info.Name)
+// applyNetwork copies host, port, protocol, proxy, login timeout, and OCSP
+// settings onto the driver configuration.
+func applyNetwork(cfg *gosnowflake.Config, info *config.Context) error {
+	if err := info.ValidateNetwork(); err != nil {
+		return fmt.Errorf("connection %q: %w", info.Name, err)
+	}
+	cfg.Host = info.Host
+	cfg.Port = info.Port
+	cfg.Protocol = info.Protocol
+	cfg.LoginTimeout = time.Duration(info.LoginTimeout) * time.Second
+	if info.OCSPFailOpen != nil {
+		cfg.OCSPFailOpen = gosnowflake.OCSPFailOpenFalse
+		if *info.OCSPFailOpen {
+			cfg.OCSPFailOpen = gosnowflake.OCSPFailOpenTrue
+		}
+	}
+	if info.Proxy != "" {
+		proxy, err := config.ParseProxy(info.Proxy)
+		if err != nil {
+			return fmt.Errorf("connection %q: %w", info.Name, err)
+		}
+		cfg.ProxyProtocol = proxy.Scheme
+		cfg.ProxyHost = proxy.Hostname()
+		if port := proxy.Port(); port != "" {
+			cfg.ProxyPort, _ = strconv.Atoi(port)
+		}
+		if proxy.User != nil {
+			cfg.ProxyUser = proxy.User.Username()
+			cfg.ProxyPassword, _ = proxy.User.Password()
+		}
+		cfg.NoProxy = info.NoProxy
 	}
-	cfg.Password = secret
+	return nil
+}
+
+// sessionTimeout extends base by the configured login timeout so slow logins
+// are not cut short by the overall deadline.
+func sessionTimeout(base time.Duration, info *config.Context) time.Duration {
+	return base + time.Duration(info.LoginTimeout)*time.Second
+}
 
+func buildDSN(info *config.Context) (string, error) {
+	cfg, err := driverConfig(info)
+	if err != nil {
+		return "", err
+	}
 	dsn, err := dsnFunc(cfg)
 	if err != nil {
 		return "", fmt.Errorf("build DSN: %w", err)
 	}
+	return dsn, nil
+}
+
+// TestConnection attempts to connect to Snowflake using the provided connection info.
+// It returns the server's CURRENT_TIMESTAMP upon success.
+func TestConnection(ctx context.Context, info *config.Context) (string, error) {
+	if info == nil {
+		return "", fmt.Errorf("connection info is required")
+	}
+	dsn, err := buildDSN(info)
+	if err != nil {
+		return "", err
+	}
 
 	db, err := openFunc("snowflake", dsn)
 	if err != nil {
@@ -50,7 +121,7 @@ func TestConnection(ctx context.Context, info *config.Context) (string, error) {
 	}
 	defer db.Close()
 
-	pingCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	pingCtx, cancel := context.WithTimeout(ctx, sessionTimeout(15*time.Second, info))
 	defer cancel()
 
 	if err := db.PingContext(pingCtx); err != nil {
@@ -65,29 +136,42 @@ func TestConnection(ctx context.Context, info *config.Context) (string, error) {
 	return serverTime, nil
 }
 
+// Column describes a result column as reported by the driver.
+type Column struct {
+	Name string `json:"name" yaml:"name"`
+	// Type is the Snowflake type name, e.g. FIXED, REAL, TEXT, DATE, TIMESTAMP_NTZ.
+	Type string `json:"type" yaml:"type"`
+}
+
+// QueryResult holds the rows of a statement together with its execution metadata.
+type QueryResult struct {
+	QueryID  string
+	Columns  []Column
+	Rows     []map[string]any
+	Duration time.Duration
+}
+
 // RunQuery executes the provided SQL and returns rows as maps.
 func RunQuery(ctx context.Context, info *config.Context, stmt string) ([]map[string]any, error) {
+	res, err := Query(ctx, info, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return res.Rows, nil
+}
+
+// Query executes the provided SQL and returns rows along with column metadata, query ID, and run time.
+// On a read-only connection, statements that are not reads are refused before a session is opened.
+func Query(ctx context.Context, info *config.Context, stmt string) (*QueryResult, error) {
 	if info == nil {
 		return nil, fmt.Errorf("connection info is required")
 	}
-	cfg := &gosnowflake.Config{
-		Account:   info.Account,
-		User:      info.User,
-		Role:      info.Role,
-		Warehouse: info.Warehouse,
-		Database:  info.Database,
-		Schema:    info.Schema,
+	if err := CheckReadOnly(info, stmt); err != nil {
+		return nil, err
 	}
-
-	secret := strings.TrimSpace(info.Secret)
-	if secret == "" {
-		return nil, fmt.Errorf("connection %q has no stored credential", info.Name)
-	}
-	cfg.Password = secret
-
-	dsn, err := dsnFunc(cfg)
+	dsn, err := buildDSN(info)
 	if err != nil {
-		return nil, fmt.Errorf("build DSN: %w", err)
+		return nil, err
 	}
 
 	db, err := openFunc("snowflake", dsn)
@@ -95,11 +179,34 @@ func RunQuery(ctx context.Context, info *config.Context, stmt string) ([]map[str
 		return nil, fmt.Errorf("open connection: %w", err)
 	}
 	defer db.Close()
+	return query(ctx, db, info, stmt)
+}
 
-	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+// CheckReadOnly refuses statements that are not reads on a read-only
+// connection with a policy error. Query and Session.Query apply it before
+// anything is sent.
+func CheckReadOnly(info *config.Context, stmt string) error {
+	if !info.IsReadOnly() {
+		return nil
+	}
+	if err := sqlstmt.CheckReadOnly(stmt); err != nil {
+		return clierror.New(clierror.CategoryPolicy, fmt.Errorf("connection %q: %w", info.Name, err))
+	}
+	return nil
+}
+
+// queryer is satisfied by *sql.DB and *sql.Conn.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func query(ctx context.Context, db queryer, info *config.Context, stmt string) (*QueryResult, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, sessionTimeout(30*time.Second, info))
 	defer cancel()
 
-	rows, err := db.QueryContext(queryCtx, stmt)
+	queryIDCh := make(chan string, 1)
+	started := time.Now()
+	rows, err := db.QueryContext(gosnowflake.WithQueryIDChan(queryCtx, queryIDCh), stmt)
 	if err != nil {
 		return nil, fmt.Errorf("execute query: %w", err)
 	}
@@ -109,6 +216,17 @@ func RunQuery(ctx context.Context, info *config.Context, stmt string) ([]map[str
 	if err != nil {
 		return nil, fmt.Errorf("fetch columns: %w", err)
 	}
+	columns := make([]Column, len(cols))
+	for i, col := range cols {
+		columns[i] = Column{Name: col}
+	}
+	if types, err := rows.ColumnTypes(); err == nil {
+		for i, ct := range types {
+			if i < len(columns) {
+				columns[i].Type = ct.DatabaseTypeName()
+			}
+		}
+	}
 
 	var results []map[string]any
 	for rows.Next() {
@@ -134,5 +252,16 @@ func RunQuery(ctx context.Context, info *config.Context, stmt string) ([]map[str
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("rows error: %w", err)
 	}
-	return results, nil
+
+	res := &QueryResult{
+		Columns:  columns,
+		Rows:     results,
+		Duration: time.Since(started),
+	}
+	select {
+	case id := <-queryIDCh:
+		res.QueryID = id
+	default:
+	}
+	return res, nil
 }
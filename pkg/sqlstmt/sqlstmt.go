@@ -0,0 +1,337 @@
+// Package sqlstmt splits SQL text into statements and classifies each one as
+// read-only or mutating, so commands can guard protected and read-only
+// connections before anything is sent to Snowflake.
+//
+// Classification is lexical: it looks at the leading keyword of each
+// statement, skipping comments, string literals, and parentheses. Anything it
+// does not recognise is treated as mutating.
+package sqlstmt
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Kind is the class of a statement.
+type Kind string
+
+const (
+	// KindRead covers queries and metadata lookups: SELECT, SHOW, DESCRIBE, ...
+	KindRead Kind = "read"
+	// KindSession covers USE, SET, UNSET, and ALTER SESSION.
+	KindSession Kind = "session"
+	// KindTransaction covers BEGIN, START TRANSACTION, COMMIT, and ROLLBACK.
+	KindTransaction Kind = "transaction"
+	// KindDML covers INSERT, UPDATE, DELETE, MERGE, TRUNCATE, COPY, PUT, and REMOVE.
+	KindDML Kind = "dml"
+	// KindDDL covers CREATE, ALTER, DROP, UNDROP, and COMMENT.
+	KindDDL Kind = "ddl"
+	// KindDCL covers GRANT and REVOKE.
+	KindDCL Kind = "dcl"
+	// KindCall covers stored procedures, EXECUTE, and Snowflake Scripting blocks.
+	KindCall Kind = "call"
+	// KindUnknown is any statement whose leading keyword is not recognised.
+	KindUnknown Kind = "unknown"
+)
+
+var keywordKinds = map[string]Kind{
+	"SELECT": KindRead, "SHOW": KindRead, "DESCRIBE": KindRead, "DESC": KindRead, "EXPLAIN": KindRead,
+	"LIST": KindRead, "LS": KindRead, "GET": KindRead, "VALUES": KindRead,
+	"USE": KindSession, "SET": KindSession, "UNSET": KindSession,
+	"START": KindTransaction, "COMMIT": KindTransaction, "ROLLBACK": KindTransaction,
+	"INSERT": KindDML, "UPDATE": KindDML, "DELETE": KindDML, "MERGE": KindDML, "TRUNCATE": KindDML,
+	"COPY": KindDML, "PUT": KindDML, "REMOVE": KindDML, "RM": KindDML,
+	"CREATE": KindDDL, "ALTER": KindDDL, "DROP": KindDDL, "UNDROP": KindDDL, "COMMENT": KindDDL,
+	"GRANT": KindDCL, "REVOKE": KindDCL,
+	"CALL": KindCall, "EXECUTE": KindCall, "DECLARE": KindCall,
+}
+
+// Statement is one statement of a SQL text.
+type Statement struct {
+	// Text is the statement as written, without the terminating semicolon.
+	Text string
+	// Keyword is the upper-cased keyword that decided Kind.
+	Keyword string
+	Kind    Kind
+}
+
+// Mutating reports whether the statement can change data, objects, or
+// grants. Session and transaction control statements are not mutating.
+func (s Statement) Mutating() bool {
+	switch s.Kind {
+	case KindRead, KindSession, KindTransaction:
+		return false
+	}
+	return true
+}
+
+// Summary returns the first line of the statement, shortened for prompts.
+func (s Statement) Summary() string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s.Text), "\n")
+	line = strings.TrimSpace(line)
+	if len(line) > 80 {
+		line = line[:77] + "..."
+	}
+	return line
+}
+
+// Split breaks sql into statements on top-level semicolons and classifies
+// each one. Statements that contain only comments are dropped.
+func Split(sql string) []Statement {
+	var stmts []Statement
+	start := 0
+	l := lexer{src: sql}
+	for {
+		tok, ok := l.next()
+		if !ok || tok.text == ";" {
+			end := len(sql)
+			if ok {
+				end = tok.pos
+			}
+			if stmt, ok := classify(sql[start:end]); ok {
+				stmts = append(stmts, stmt)
+			}
+			if !ok {
+				return stmts
+			}
+			start = end + 1
+		}
+	}
+}
+
+// Mutating returns the statements of stmts that are mutating.
+func Mutating(stmts []Statement) []Statement {
+	var res []Statement
+	for _, s := range stmts {
+		if s.Mutating() {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// ReadOnlyError reports a statement refused on a read-only connection.
+type ReadOnlyError struct {
+	Statement Statement
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("refusing %s statement on a read-only connection (%s): %s", e.Statement.Keyword, e.Statement.Kind, e.Statement.Summary())
+}
+
+// CheckReadOnly returns a *ReadOnlyError for the first mutating statement in
+// sql, or nil when every statement only reads.
+func CheckReadOnly(sql string) error {
+	if mutating := Mutating(Split(sql)); len(mutating) > 0 {
+		return &ReadOnlyError{Statement: mutating[0]}
+	}
+	return nil
+}
+
+func classify(text string) (Statement, bool) {
+	words := topLevelWords(text)
+	if len(words) == 0 {
+		return Statement{}, false
+	}
+	// Drop comments that precede the statement, e.g. a trailing comment on
+	// the line of the previous one.
+	first := lexer{src: text}
+	if tok, ok := first.next(); ok {
+		text = text[tok.pos:]
+	}
+	stmt := Statement{Text: strings.TrimSpace(text), Keyword: words[0], Kind: KindUnknown}
+	if kind, ok := keywordKinds[stmt.Keyword]; ok {
+		stmt.Kind = kind
+	}
+	switch stmt.Keyword {
+	case "WITH":
+		// The statement after the common table expressions decides. When it
+		// cannot be found the statement stays unknown, and so mutating.
+		stmt.Kind = KindUnknown
+		if verb, ok := withVerb(text); ok {
+			if kind, ok := keywordKinds[verb]; ok && (kind == KindRead || kind == KindDML) {
+				stmt.Keyword, stmt.Kind = verb, kind
+			}
+		}
+	case "ALTER":
+		if len(words) > 1 && words[1] == "SESSION" {
+			stmt.Kind = KindSession
+		}
+	case "BEGIN":
+		// BEGIN [TRANSACTION|WORK] opens a transaction; anything else starts a
+		// Snowflake Scripting block.
+		stmt.Kind = KindCall
+		if len(words) == 1 || words[1] == "TRANSACTION" || words[1] == "WORK" || words[1] == "NAME" {
+			stmt.Kind = KindTransaction
+		}
+	}
+	return stmt, true
+}
+
+// withVerb returns the upper-cased keyword that follows the common table
+// expressions of a WITH statement:
+//
+//	WITH [RECURSIVE] name [(columns)] AS (query) [, ...] verb ...
+//
+// CTE names are never taken for the verb, even when they read like one (get,
+// list, values, ...). It reports false when text does not have that shape.
+func withVerb(text string) (string, bool) {
+	l := lexer{src: text}
+	if tok, ok := l.next(); !ok || !strings.EqualFold(tok.text, "WITH") {
+		return "", false
+	}
+	tok, ok := l.next()
+	if ok && strings.EqualFold(tok.text, "RECURSIVE") {
+		tok, ok = l.next()
+	}
+	for ok {
+		// The name; quoted names are skipped by the lexer.
+		if tok.word && !strings.EqualFold(tok.text, "AS") {
+			tok, ok = l.next()
+		}
+		if ok && tok.text == "(" {
+			l.skipParens()
+			tok, ok = l.next()
+		}
+		if !ok || !strings.EqualFold(tok.text, "AS") {
+			return "", false
+		}
+		if tok, ok = l.next(); !ok || tok.text != "(" {
+			return "", false
+		}
+		l.skipParens()
+		if tok, ok = l.next(); !ok {
+			return "", false
+		}
+		if tok.text != "," {
+			if !tok.word {
+				return "", false
+			}
+			return strings.ToUpper(tok.text), true
+		}
+		tok, ok = l.next()
+	}
+	return "", false
+}
+
+// topLevelWords returns the upper-cased bare words of text outside
+// parentheses, strings, and comments.
+func topLevelWords(text string) []string {
+	var words []string
+	depth := 0
+	l := lexer{src: text}
+	for {
+		tok, ok := l.next()
+		if !ok {
+			return words
+		}
+		switch {
+		case tok.text == "(":
+			depth++
+		case tok.text == ")":
+			if depth > 0 {
+				depth--
+			}
+		case tok.word && depth == 0:
+			words = append(words, strings.ToUpper(tok.text))
+		case tok.word && len(words) == 0:
+			// A statement wrapped in parentheses, e.g. (SELECT 1).
+			words = append(words, strings.ToUpper(tok.text))
+		}
+	}
+}
+
+type token struct {
+	text string
+	pos  int
+	word bool
+}
+
+// lexer yields words and punctuation, skipping whitespace, comments, quoted
+// strings and identifiers, and $$-delimited bodies.
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, bool) {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		rest := l.src[l.pos:]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			l.pos++
+		case strings.HasPrefix(rest, "--"), strings.HasPrefix(rest, "//"):
+			l.skipPast("\n")
+		case strings.HasPrefix(rest, "/*"):
+			l.pos += 2
+			l.skipPast("*/")
+		case strings.HasPrefix(rest, "$$"):
+			l.pos += 2
+			l.skipPast("$$")
+		case c == '\'' || c == '"':
+			l.skipQuoted(c)
+		case isWordByte(c):
+			start := l.pos
+			for l.pos < len(l.src) && isWordByte(l.src[l.pos]) {
+				l.pos++
+			}
+			return token{text: l.src[start:l.pos], pos: start, word: true}, true
+		default:
+			l.pos++
+			return token{text: string(c), pos: l.pos - 1}, true
+		}
+	}
+	return token{}, false
+}
+
+// skipParens consumes tokens up to the parenthesis that closes one already
+// consumed.
+func (l *lexer) skipParens() {
+	depth := 1
+	for depth > 0 {
+		tok, ok := l.next()
+		if !ok {
+			return
+		}
+		switch tok.text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+		}
+	}
+}
+
+func (l *lexer) skipPast(delim string) {
+	if i := strings.Index(l.src[l.pos:], delim); i >= 0 {
+		l.pos += i + len(delim)
+		return
+	}
+	l.pos = len(l.src)
+}
+
+// skipQuoted skips a quoted literal, honouring doubled quotes and, in
+// single-quoted strings, backslash escapes.
+func (l *lexer) skipQuoted(quote byte) {
+	l.pos++
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\\' && quote == '\'':
+			l.pos += 2
+		case c == quote && l.pos+1 < len(l.src) && l.src[l.pos+1] == quote:
+			l.pos += 2
+		case c == quote:
+			l.pos++
+			return
+		default:
+			l.pos++
+		}
+	}
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
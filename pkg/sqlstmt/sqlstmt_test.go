@@ -0,0 +1,81 @@
+package sqlstmt
+
+import "testing"
+
+func TestSplitClassifiesStatements(t *testing.T) {
+	cases := []struct {
+		sql  string
+		kind Kind
+	}{
+		{"select 1", KindRead},
+		{"  -- leading comment\n/* block */ SELECT * FROM t", KindRead},
+		{"(select 1)", KindRead},
+		{"show warehouses", KindRead},
+		{"with x as (select 1) select * from x", KindRead},
+		{"with x as (select 1) delete from t using x", KindDML},
+		{"with recursive x (n) as (select 1 union all select n + 1 from x where n < 3) select n from x", KindRead},
+		{"with a as (select 1), b as (select (2)) insert into t select * from a, b", KindDML},
+		{"with get as (select id from t) delete from t using get", KindDML},
+		{"with list as (select 1), ls as (select 2) update t set x = 1", KindDML},
+		{"WITH values AS (SELECT 1) MERGE INTO t USING values ON t.id = values.id WHEN MATCHED THEN DELETE", KindDML},
+		{"with show (n) as (select 1) delete from t", KindDML},
+		{"with desc as (select 1), \"select\" as (select 2) truncate table t", KindDML},
+		{"with values as (select 1) select * from values", KindRead},
+		{"with p as procedure () returns int language sql as $$ begin delete from t; return 1; end $$ call p()", KindUnknown},
+		{"with get as (select 1)", KindUnknown},
+		{"with x as (select 1) (select * from x)", KindUnknown},
+		{"delete from orders", KindDML},
+		{"merge into t using s on t.id = s.id when matched then delete", KindDML},
+		{"create or replace table t (id int)", KindDDL},
+		{"alter session set query_tag = 'x'", KindSession},
+		{"alter warehouse wh suspend", KindDDL},
+		{"grant role analyst to user bob", KindDCL},
+		{"call refresh_all()", KindCall},
+		{"execute immediate $$ delete from t $$", KindCall},
+		{"begin", KindTransaction},
+		{"begin insert into t values (1); end", KindCall},
+		{"commit", KindTransaction},
+		{"use role sysadmin", KindSession},
+		{"vacuum t", KindUnknown},
+	}
+	for _, tc := range cases {
+		stmts := Split(tc.sql)
+		if len(stmts) == 0 || stmts[0].Kind != tc.kind {
+			t.Fatalf("Split(%q) = %+v, want kind %s", tc.sql, stmts, tc.kind)
+		}
+	}
+}
+
+func TestSplitHonoursQuotesAndComments(t *testing.T) {
+	sql := `select 'a;b', "weird;name" from t; -- trailing; comment
+	insert into t values ('it''s; fine', $$x;y$$);
+	/* only a comment; */`
+	stmts := Split(sql)
+	if len(stmts) != 2 {
+		t.Fatalf("expected 2 statements, got %d: %+v", len(stmts), stmts)
+	}
+	if stmts[0].Kind != KindRead || stmts[1].Kind != KindDML {
+		t.Fatalf("unexpected kinds: %+v", stmts)
+	}
+	if got := Mutating(stmts); len(got) != 1 || got[0].Keyword != "INSERT" {
+		t.Fatalf("unexpected mutating statements: %+v", got)
+	}
+	if stmts[1].Summary() != "insert into t values ('it''s; fine', $$x;y$$)" {
+		t.Fatalf("unexpected summary %q", stmts[1].Summary())
+	}
+}
+
+func TestCheckReadOnly(t *testing.T) {
+	if err := CheckReadOnly("select 1; show tables; use role analyst"); err != nil {
+		t.Fatalf("expected reads to pass, got %v", err)
+	}
+	err := CheckReadOnly("select 1; call purge_all()")
+	roErr, ok := err.(*ReadOnlyError)
+	if !ok || roErr.Statement.Keyword != "CALL" {
+		t.Fatalf("expected CALL to be refused, got %v", err)
+	}
+	err = CheckReadOnly("with get as (select id from orders) delete from orders using get")
+	if roErr, ok := err.(*ReadOnlyError); !ok || roErr.Statement.Keyword != "DELETE" {
+		t.Fatalf("expected a CTE named get not to hide DELETE, got %v", err)
+	}
+}
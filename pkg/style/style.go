@@ -0,0 +1,86 @@
+// Package style adds ANSI colors to human-facing output. Styling switches off
+// automatically for non-TTY writers, when NO_COLOR is set, for TERM=dumb, or
+// when --no-color is passed.
+package style
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+const (
+	reset  = "\x1b[0m"
+	bold   = "\x1b[1m"
+	dim    = "\x1b[2m"
+	red    = "\x1b[31m"
+	green  = "\x1b[32m"
+	yellow = "\x1b[33m"
+	blue   = "\x1b[34m"
+	cyan   = "\x1b[36m"
+)
+
+// Styler wraps text in ANSI escape sequences when enabled; otherwise it returns text unchanged.
+type Styler struct {
+	enabled bool
+}
+
+// New returns a Styler that always (enabled=true) or never styles text.
+func New(enabled bool) Styler {
+	return Styler{enabled: enabled}
+}
+
+// For returns a Styler for w, honouring TTY detection, NO_COLOR, TERM=dumb, and the --no-color flag.
+func For(w io.Writer, noColor bool) Styler {
+	return Styler{enabled: Enabled(w, noColor)}
+}
+
+// Enabled reports whether styling should be written to w.
+func Enabled(w io.Writer, noColor bool) bool {
+	if noColor {
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// Enabled reports whether the Styler emits escape sequences.
+func (s Styler) Enabled() bool { return s.enabled }
+
+func (s Styler) wrap(code, text string) string {
+	if !s.enabled || text == "" {
+		return text
+	}
+	return code + text + reset
+}
+
+// Bold emphasises headings.
+func (s Styler) Bold(text string) string { return s.wrap(bold, text) }
+
+// Dim de-emphasises secondary text such as labels.
+func (s Styler) Dim(text string) string { return s.wrap(dim, text) }
+
+// Error colors failures red.
+func (s Styler) Error(text string) string { return s.wrap(red, text) }
+
+// Success colors positive states such as the current connection green.
+func (s Styler) Success(text string) string { return s.wrap(green, text) }
+
+// Warning colors cautionary text yellow.
+func (s Styler) Warning(text string) string { return s.wrap(yellow, text) }
+
+// Key colors object keys in JSON/YAML documents.
+func (s Styler) Key(text string) string { return s.wrap(blue, text) }
+
+// Accent highlights values worth noticing, such as the default connection.
+func (s Styler) Accent(text string) string { return s.wrap(cyan, text) }
@@ -0,0 +1,29 @@
+package style
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestStylerDisabledReturnsPlainText(t *testing.T) {
+	if got := New(false).Error("boom"); got != "boom" {
+		t.Fatalf("expected plain text, got %q", got)
+	}
+	if got := New(true).Error("boom"); got != red+"boom"+reset {
+		t.Fatalf("expected red text, got %q", got)
+	}
+}
+
+func TestEnabledRespectsWriterAndFlags(t *testing.T) {
+	if Enabled(&bytes.Buffer{}, false) {
+		t.Fatalf("expected non-TTY writer to disable styling")
+	}
+	if Enabled(os.Stdout, true) {
+		t.Fatalf("expected --no-color to disable styling")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if Enabled(os.Stdout, false) {
+		t.Fatalf("expected NO_COLOR to disable styling")
+	}
+}